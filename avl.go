@@ -0,0 +1,39 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// rebalanceAVL restores the AVL property at this node: the heights of its two
+// sides are allowed to differ by at most one. Every node below has already
+// fixed itself by the time this runs, so a single or double rotation here is
+// always enough.
+func (n *node[T]) rebalanceAVL() {
+	lean := n.left.height - n.right.height
+
+	switch {
+	case lean > 1:
+		// a left child that leans right has to be straightened out first,
+		// otherwise the rotation just moves the problem to the other side
+		if n.left.lean < 0 {
+			n.rotateChild(&n.left, false)
+		}
+		n.rotate(true)
+
+	case lean < -1:
+		if n.right.lean > 0 {
+			n.rotateChild(&n.right, true)
+		}
+		n.rotate(false)
+	}
+}
@@ -0,0 +1,284 @@
+package goroutree_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// shape is a tree of ints rebuilt from Print output, so tests can look at how
+// the nodes are laid out.
+type shape struct {
+	val         int
+	red         bool
+	left, right *shape
+}
+
+func (s *shape) height() int {
+	if s == nil {
+		return 0
+	}
+	return 1 + max(s.left.height(), s.right.height())
+}
+
+func printed(t *testing.T, g interface {
+	Print(chan struct{}, io.Writer) error
+}) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	structreschan := make(chan struct{})
+	if err := g.Print(structreschan, buf); err != nil {
+		t.Fatalf("Expected no error from print, got %v", err)
+	}
+	<-structreschan
+
+	return buf.String()
+}
+
+// shapeOf prints the tree and rebuilds it. Print goes in order and indents each
+// value by its depth, which is enough to tell where every node is: the
+// shallowest value in any run is the top of that subtree.
+func shapeOf(t *testing.T, g *goroutree.Goroutree[int]) *shape {
+	t.Helper()
+
+	type line struct {
+		val, depth int
+		red        bool
+	}
+	var lines []line
+
+	for _, l := range strings.Split(printed(t, g), "\n") {
+		if l == "" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(l, " ")
+		v, err := strconv.Atoi(strings.Fields(trimmed)[0])
+		if err != nil {
+			t.Fatalf("Expected an int in print output, got %q", l)
+		}
+		lines = append(lines, line{v, len(l) - len(trimmed), strings.HasSuffix(l, " red")})
+	}
+
+	var build func(lines []line, depth int) *shape
+	build = func(lines []line, depth int) *shape {
+		if len(lines) == 0 {
+			return nil
+		}
+
+		top := slices.IndexFunc(lines, func(l line) bool { return l.depth == depth })
+		if top < 0 {
+			t.Fatalf("Expected a node at depth %d in %v", depth, lines)
+		}
+
+		return &shape{
+			val:   lines[top].val,
+			red:   lines[top].red,
+			left:  build(lines[:top], depth+1),
+			right: build(lines[top+1:], depth+1),
+		}
+	}
+
+	return build(lines, 0)
+}
+
+// checkAVL fails unless every node in the tree has sides whose heights differ
+// by at most one.
+func checkAVL(t *testing.T, g *goroutree.Goroutree[int]) {
+	t.Helper()
+
+	var walk func(s *shape)
+	walk = func(s *shape) {
+		if s == nil {
+			return
+		}
+
+		if d := s.left.height() - s.right.height(); d > 1 || d < -1 {
+			t.Fatalf("Expected node %d to be balanced, got heights %d and %d", s.val, s.left.height(), s.right.height())
+		}
+
+		walk(s.left)
+		walk(s.right)
+	}
+
+	walk(shapeOf(t, g))
+}
+
+func TestAVL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SortedInserts", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		for i := 0; i < 15; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// the same perfect tree balancedOrder builds without any rotations
+		ref := goroutree.NewOrdered[int]()
+		defer ref.Close()
+
+		for _, i := range balancedOrder {
+			ref.InsertCtx(ctx, i)
+		}
+
+		if got, expected := printed(t, g), printed(t, ref); got != expected {
+			t.Fatalf("Expected a perfectly balanced tree:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("DoubleRotation", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		for _, i := range []int{3, 1, 2} {
+			g.InsertCtx(ctx, i)
+		}
+
+		if got := printed(t, g); got != " 1\n2\n 3\n" {
+			t.Fatalf("Expected 2 at the root, got:\n%s", got)
+		}
+	})
+	t.Run("Depth", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		for i := 0; i < 1000; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// an AVL tree of 1000 nodes is at most 14 deep
+		if h := shapeOf(t, g).height(); h > 14 {
+			t.Fatalf("Expected a height of at most 14, got %d", h)
+		}
+		checkAVL(t, g)
+		checkLen(t, g, 1000)
+	})
+	t.Run("Random", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		rng := rand.New(rand.NewSource(1))
+		model := map[int]bool{}
+
+		for i := 0; i < 2000; i++ {
+			v := rng.Intn(200)
+
+			if rng.Intn(2) == 0 {
+				if ok, _ := g.InsertCtx(ctx, v); ok == model[v] {
+					t.Fatalf("Expected insert %d to return %v", v, !model[v])
+				}
+				model[v] = true
+			} else {
+				if ok, _ := g.DeleteCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected delete %d to return %v", v, model[v])
+				}
+				delete(model, v)
+			}
+
+			if i%100 == 0 {
+				checkAVL(t, g)
+			}
+		}
+
+		checkAVL(t, g)
+
+		var expected []int
+		for v := range model {
+			expected = append(expected, v)
+		}
+		slices.Sort(expected)
+
+		checkOrder(t, g, expected)
+	})
+	t.Run("PopMin", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		for i := 0; i < 100; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		for i := 0; i < 50; i++ {
+			if v, ok, err := g.PopMin(); v != i || !ok || err != nil {
+				t.Fatalf("Expected %d from pop, got %d, %v, %v", i, v, ok, err)
+			}
+		}
+
+		checkAVL(t, g)
+		checkLen(t, g, 50)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		// every worker owns its own values, so each one knows exactly what
+		// its reads should see no matter how the others move the tree around
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < 100; i++ {
+					v := i*8 + w
+					if ok, err := g.InsertCtx(ctx, v); !ok || err != nil {
+						t.Errorf("Expected a true result from inserting %d, got %v, %v", v, ok, err)
+						return
+					}
+					if ok, _ := g.ContainsCtx(ctx, v); !ok {
+						t.Errorf("Expected %d to be found right after inserting it", v)
+						return
+					}
+					if i%2 == 1 {
+						if ok, _ := g.DeleteCtx(ctx, v); !ok {
+							t.Errorf("Expected a true result from deleting %d", v)
+							return
+						}
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		var expected []int
+		for i := 0; i < 100; i += 2 {
+			for w := 0; w < 8; w++ {
+				expected = append(expected, i*8+w)
+			}
+		}
+		slices.Sort(expected)
+
+		checkAVL(t, g)
+		checkOrder(t, g, expected)
+	})
+	t.Run("Map", func(t *testing.T) {
+		m := goroutree.NewMap[int, string](goroutree.WithBalance(goroutree.AVL))
+		defer m.Close()
+
+		// rotations swap values between nodes, and what's stored under each key
+		// has to go with it
+		for i := 0; i < 50; i++ {
+			m.Put(i, strconv.Itoa(i))
+		}
+		for i := 0; i < 50; i += 3 {
+			m.Remove(i)
+		}
+
+		for i := 0; i < 50; i++ {
+			v, ok, _ := m.Get(i)
+			if ok != (i%3 != 0) || (ok && v != strconv.Itoa(i)) {
+				t.Fatalf("Expected the right value for %d, got %q, %v", i, v, ok)
+			}
+		}
+	})
+}
@@ -0,0 +1,298 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "sync"
+
+// A batch goes down the tree as one command. Each node answers for the values
+// in it that match its own, splits the rest into the ones smaller and the ones
+// bigger than its value and sends each of those on as a batch of its own, so
+// only one command goes down any link no matter how many values are headed
+// that way.
+//
+// Contains batches don't wait for anything, the same as a single Contains. A
+// write batch holds the path down the same way a single write does: each node
+// sends its pieces to its children one at a time and waits to hear back from
+// each, then fixes itself up once, on the way back up. That only works when a
+// node can fix itself no matter how much has changed underneath it, which is
+// true of an Unbalanced tree and a Treap but not of the others, so for those
+// the manager takes the values one at a time instead.
+
+type batchOp int
+
+const (
+	batchInsert batchOp = iota
+	batchContains
+	batchDelete
+)
+
+// batchResults is shared by every piece of a batch. Each value has its own spot
+// in ok and errs, so no two pieces ever write to the same place. Every piece is
+// added to wg before it's sent, and marked done once the node that got it has
+// finished with it.
+type batchResults struct {
+	ok   []bool
+	errs []error
+	wg   sync.WaitGroup
+}
+
+// batchCmd is one piece of a batch. idx holds where each of vals was in the
+// slice the caller passed in.
+type batchCmd[T any] struct {
+	op   batchOp
+	vals []T
+	idx  []int
+	res  *batchResults
+
+	// for writes, the same as insertCmd and deleteCmd
+	ack chan subtree
+}
+
+func (c batchCmd[T]) typ() cmdType {
+	return ctBatch
+}
+
+// piece makes a batch of some of the values in this one, ready to be sent on.
+func (c batchCmd[T]) piece(vals []T, idx []int) batchCmd[T] {
+	c.res.wg.Add(1)
+
+	return batchCmd[T]{
+		op:   c.op,
+		vals: vals,
+		idx:  idx,
+		res:  c.res,
+	}
+}
+
+// fansOut says whether the batch can be split up on its way down the tree.
+func (c batchCmd[T]) fansOut(cfg *config[T]) bool {
+	switch cfg.balance {
+	case Unbalanced, Treap:
+		return true
+	case Splay:
+		// finding a value moves it in a Splay tree
+		return false
+	default:
+		return c.op == batchContains
+	}
+}
+
+// plant fills an empty spot in the tree with the values in an insert batch. The
+// first one gets a new node there and the rest are sent down to it, the same
+// as if they had been inserted one at a time.
+func (c batchCmd[T]) plant(spot *subtree, cfg *config[T], ackchan chan subtree) {
+	*spot = newNode(c.vals[0], nil, cfg).spawn()
+	c.res.ok[c.idx[0]] = true
+
+	if len(c.vals) == 1 {
+		return
+	}
+
+	rest := c.piece(c.vals[1:], c.idx[1:])
+	rest.ack = ackchan
+	spot.ch <- rest
+	*spot = <-ackchan
+}
+
+// InsertBatch adds all of vals to the set and returns, for each one, whether
+// it was inserted, the same as Insert would. A value that's in vals more than
+// once is only inserted the first time, unless the tree is a Multiset.
+//
+// The returned error is ErrClosed if the tree is closed, or otherwise the
+// first error from a value that couldn't be compared, which is false in the
+// results.
+//
+// The batch is only split up on its way down an Unbalanced tree or a Treap.
+// An AVL, RedBlack or Splay tree can't fix itself up after more than one
+// change at a time, so there the manager inserts the values one after another
+// instead, each going all the way down the tree on its own. That still saves
+// every value its own trip from the caller, but nothing else can use the tree
+// until the whole batch is done.
+func (g *Goroutree[T]) InsertBatch(vals []T) ([]bool, error) {
+	return g.batch(batchInsert, vals)
+}
+
+// ContainsBatch checks whether each of vals is in the set, in a single trip
+// down the tree. See InsertBatch for the results. In a Splay tree, where
+// finding a value moves it, the values are looked up one after another
+// instead.
+func (g *Goroutree[T]) ContainsBatch(vals []T) ([]bool, error) {
+	return g.batch(batchContains, vals)
+}
+
+// DeleteBatch removes all of vals from the set and returns, for each one,
+// whether it was deleted, the same as Delete would. See InsertBatch for the
+// results, and for which trees the batch is split up on its way down. In the
+// others the values are deleted one after another.
+func (g *Goroutree[T]) DeleteBatch(vals []T) ([]bool, error) {
+	return g.batch(batchDelete, vals)
+}
+
+func (g *Goroutree[T]) batch(op batchOp, vals []T) ([]bool, error) {
+	res := &batchResults{
+		ok:   make([]bool, len(vals)),
+		errs: make([]error, len(vals)),
+	}
+
+	idx := make([]int, len(vals))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	res.wg.Add(1)
+	err := g.send(batchCmd[T]{
+		op:   op,
+		vals: vals,
+		idx:  idx,
+		res:  res,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.wg.Wait()
+
+	for _, err := range res.errs {
+		if err != nil {
+			return res.ok, err
+		}
+	}
+
+	return res.ok, nil
+}
+
+// batch returns true if this node has removed itself from the tree.
+func (n *node[T]) batch(c batchCmd[T]) bool {
+	defer c.res.wg.Done()
+
+	// split the batch up around this node's value, keeping the order the
+	// values came in
+	var leftVals, rightVals []T
+	var leftIdx, rightIdx, mine []int
+
+	for i, val := range c.vals {
+		comparison, err := n.cfg.compare(val, n.val)
+		switch {
+		case err != nil:
+			c.res.errs[c.idx[i]] = err
+		case comparison < 0:
+			leftVals = append(leftVals, val)
+			leftIdx = append(leftIdx, c.idx[i])
+		case comparison > 0:
+			rightVals = append(rightVals, val)
+			rightIdx = append(rightIdx, c.idx[i])
+		default:
+			mine = append(mine, c.idx[i])
+		}
+	}
+
+	if c.op == batchContains {
+		for _, i := range mine {
+			c.res.ok[i] = true
+		}
+
+		if len(leftVals) > 0 && n.left.ch != nil {
+			n.left.ch <- c.piece(leftVals, leftIdx)
+		}
+		if len(rightVals) > 0 && n.right.ch != nil {
+			n.right.ch <- c.piece(rightVals, rightIdx)
+		}
+
+		return false
+	}
+
+	for _, side := range []struct {
+		child *subtree
+		vals  []T
+		idx   []int
+	}{
+		{&n.left, leftVals, leftIdx},
+		{&n.right, rightVals, rightIdx},
+	} {
+		if len(side.vals) == 0 {
+			continue
+		}
+
+		p := c.piece(side.vals, side.idx)
+
+		if side.child.ch == nil {
+			// an insert fills the empty spot, and a delete has nothing there
+			// to take out
+			if c.op == batchInsert {
+				p.plant(side.child, n.cfg, n.ackchan)
+			}
+			p.res.wg.Done()
+			continue
+		}
+
+		p.ack = n.ackchan
+		n.forward(side.child, p)
+	}
+
+	if c.op == batchInsert {
+		// the value was already here, so only a multiset counts it again
+		if n.cfg.multiset {
+			for _, idx := range mine {
+				n.count++
+				c.res.ok[idx] = true
+			}
+		}
+
+		n.rebalance()
+		c.ack <- n.subtree()
+		return false
+	}
+
+	for _, idx := range mine {
+		if n.count == 0 {
+			break
+		}
+
+		n.count--
+		c.res.ok[idx] = true
+	}
+
+	if n.count > 0 || len(mine) == 0 {
+		n.rebalance()
+		c.ack <- n.subtree()
+		return false
+	}
+
+	// the last occurrence is gone, so the node goes too, the same way it does
+	// for a single delete
+	if n.left.ch == nil || n.right.ch == nil {
+		promoted := n.left
+		if n.right.ch != nil {
+			promoted = n.right
+		}
+
+		c.ack <- promoted
+		return true
+	}
+
+	reschan := make(chan subtreeMinResponse[T])
+	n.right.ch <- extractMinCmd[T]{reschan: reschan}
+
+	res := <-reschan
+	n.val = res.val
+	n.payload = res.payload
+	n.count = res.count
+	n.prio = res.prio
+	n.right = res.rest
+	n.rebalance()
+
+	c.ack <- n.subtree()
+	return false
+}
@@ -0,0 +1,217 @@
+package goroutree_test
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Results", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		ok, err := g.InsertBatch([]int{5, 3, 8, 3, 1, 9, 5})
+		if expected := []bool{true, true, true, false, true, true, false}; !slices.Equal(ok, expected) || err != nil {
+			t.Fatalf("Expected %v from inserting, got %v, %v", expected, ok, err)
+		}
+
+		ok, err = g.ContainsBatch([]int{9, 2, 5, 1, 7})
+		if expected := []bool{true, false, true, true, false}; !slices.Equal(ok, expected) || err != nil {
+			t.Fatalf("Expected %v from contains, got %v, %v", expected, ok, err)
+		}
+
+		ok, err = g.DeleteBatch([]int{5, 4, 5, 1})
+		if expected := []bool{true, false, false, true}; !slices.Equal(ok, expected) || err != nil {
+			t.Fatalf("Expected %v from deleting, got %v, %v", expected, ok, err)
+		}
+
+		checkOrder(t, g, []int{3, 8, 9})
+	})
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		if ok, err := g.ContainsBatch(nil); len(ok) != 0 || err != nil {
+			t.Fatalf("Expected nothing from an empty batch, got %v, %v", ok, err)
+		}
+		if ok, err := g.DeleteBatch([]int{1, 2}); !slices.Equal(ok, []bool{false, false}) || err != nil {
+			t.Fatalf("Expected nothing deleted from an empty tree, got %v, %v", ok, err)
+		}
+	})
+	t.Run("Shape", func(t *testing.T) {
+		// an unbalanced tree ends up just like it would from inserting the
+		// values one at a time
+		vals := rand.New(rand.NewSource(1)).Perm(100)
+
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		g.InsertBatch(vals)
+
+		ref := goroutree.NewOrdered[int]()
+		defer ref.Close()
+
+		for _, v := range vals {
+			ref.InsertCtx(ctx, v)
+		}
+
+		if got, expected := printed(t, g), printed(t, ref); got != expected {
+			t.Fatalf("Expected the same tree as inserting one at a time:\n%s\ngot:\n%s", expected, got)
+		}
+
+		g.DeleteBatch(vals[:50])
+		for _, v := range vals[:50] {
+			ref.DeleteCtx(ctx, v)
+		}
+
+		if got, expected := printed(t, g), printed(t, ref); got != expected {
+			t.Fatalf("Expected the same tree as deleting one at a time:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Random", func(t *testing.T) {
+		for _, b := range []goroutree.Balance{goroutree.Unbalanced, goroutree.AVL, goroutree.RedBlack, goroutree.Treap, goroutree.Splay} {
+			g := goroutree.NewOrdered[int](goroutree.WithBalance(b))
+
+			rng := rand.New(rand.NewSource(int64(b)))
+			model := map[int]bool{}
+
+			for round := 0; round < 50; round++ {
+				vals := make([]int, rng.Intn(40))
+				for i := range vals {
+					vals[i] = rng.Intn(200)
+				}
+
+				var expected []bool
+				switch rng.Intn(3) {
+				case 0:
+					for _, v := range vals {
+						expected = append(expected, !model[v])
+						model[v] = true
+					}
+					if ok, _ := g.InsertBatch(vals); !slices.Equal(ok, expected) {
+						t.Fatalf("Expected %v from inserting %v in %v, got %v", expected, vals, b, ok)
+					}
+				case 1:
+					for _, v := range vals {
+						expected = append(expected, model[v])
+					}
+					if ok, _ := g.ContainsBatch(vals); !slices.Equal(ok, expected) {
+						t.Fatalf("Expected %v from contains %v in %v, got %v", expected, vals, b, ok)
+					}
+				case 2:
+					for _, v := range vals {
+						expected = append(expected, model[v])
+						delete(model, v)
+					}
+					if ok, _ := g.DeleteBatch(vals); !slices.Equal(ok, expected) {
+						t.Fatalf("Expected %v from deleting %v in %v, got %v", expected, vals, b, ok)
+					}
+				}
+			}
+
+			switch b {
+			case goroutree.AVL:
+				checkAVL(t, g)
+			case goroutree.RedBlack:
+				checkRB(t, g)
+			}
+
+			var sorted []int
+			for v := range model {
+				sorted = append(sorted, v)
+			}
+			slices.Sort(sorted)
+
+			checkOrder(t, g, sorted)
+			g.Close()
+		}
+	})
+	t.Run("Treap", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap))
+		defer g.Close()
+
+		var vals []int
+		for i := 0; i < 1000; i++ {
+			vals = append(vals, i)
+		}
+		g.InsertBatch(vals)
+
+		// sorted values stay shallow, same as inserting them one at a time
+		if h := shapeOf(t, g).height(); h > 40 {
+			t.Fatalf("Expected a height of at most 40, got %d", h)
+		}
+		checkLen(t, g, 1000)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		g.InsertBatch([]int{4, 2, 4, 6, 4})
+		if c, _ := g.Count(4); c != 3 {
+			t.Fatalf("Expected a count of 3, got %d", c)
+		}
+
+		ok, _ := g.DeleteBatch([]int{4, 4, 2, 2})
+		if expected := []bool{true, true, true, false}; !slices.Equal(ok, expected) {
+			t.Fatalf("Expected %v from deleting, got %v", expected, ok)
+		}
+		checkOrder(t, g, []int{4, 6})
+	})
+	t.Run("Comparer", func(t *testing.T) {
+		g := goroutree.New()
+		defer g.Close()
+
+		g.InsertCtx(ctx, goroutree.Int(5))
+
+		// the value that can't be compared fails on its own and the rest
+		// still go in
+		ok, err := g.InsertBatch([]goroutree.Comparer{goroutree.Int(3), Str("a"), goroutree.Int(7)})
+		if expected := []bool{true, false, true}; !slices.Equal(ok, expected) || err != goroutree.NotComparable {
+			t.Fatalf("Expected %v and NotComparable from inserting, got %v, %v", expected, ok, err)
+		}
+		checkLen(t, g, 3)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				var vals []int
+				for i := 0; i < 100; i++ {
+					vals = append(vals, i*8+w)
+				}
+
+				for _, batch := range [](func([]int) ([]bool, error)){g.InsertBatch, g.ContainsBatch, g.DeleteBatch, g.InsertBatch} {
+					ok, err := batch(vals)
+					if err != nil || slices.Contains(ok, false) {
+						t.Errorf("Expected every value to succeed, got %v, %v", ok, err)
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		checkLen(t, g, 800)
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, err := g.InsertBatch([]int{1}); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from inserting, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,489 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// A BTree is kept in shape from the top down. On the way down, an insert
+// splits any full node before going into it, so there is always room for the
+// value that comes up out of a split below. A delete makes sure every node it
+// goes into can spare a value, by merging it with a neighbour (and splitting
+// that back up if it's too big). Nothing ever has to be fixed on the way back
+// up, so the acks only carry sizes.
+//
+// Splits and merges change which goroutine holds which values, but only for
+// nodes just under the one doing it, while it holds the path down. Commands
+// already past it are further down, in subtrees that are moved whole.
+
+// splitCmd asks a node to keep the values before at, give the value at at to
+// its parent, and hand everything after it to a new node.
+type splitCmd[T any] struct {
+	reschan chan splitResult[T]
+	at      int
+}
+
+func (c splitCmd[T]) typ() cmdType {
+	return ctSplit
+}
+
+type splitResult[T any] struct {
+	median      T
+	left, right subtree
+}
+
+// mergeCmd asks a node to take sep and everything in from, which is the next
+// node along under the same parent.
+type mergeCmd[T any] struct {
+	sep  T
+	from subtree
+	ack  chan subtree
+}
+
+func (c mergeCmd[T]) typ() cmdType {
+	return ctMerge
+}
+
+// drainCmd asks a node to give up everything it holds and stop.
+type drainCmd[T any] struct {
+	reschan chan drainResult[T]
+}
+
+func (c drainCmd[T]) typ() cmdType {
+	return ctDrain
+}
+
+type drainResult[T any] struct {
+	keys     []T
+	children []subtree
+}
+
+// bnode is the state owned by a single BTree node goroutine.
+type bnode[T any] struct {
+	cfg *config[T]
+
+	cmdchan chan cmd
+	ackchan chan subtree
+
+	// keys is sorted, and in a node that isn't a leaf there's one more child
+	// than there are keys: children[i] holds everything between keys[i-1] and
+	// keys[i].
+	keys     []T
+	children []subtree
+}
+
+func newBNode[T any](cfg *config[T], keys []T, children []subtree) *bnode[T] {
+	return &bnode[T]{
+		cfg:      cfg,
+		cmdchan:  make(chan cmd),
+		ackchan:  make(chan subtree),
+		keys:     keys,
+		children: children,
+	}
+}
+
+// spawn starts the node's goroutine and returns what its parent needs to know
+// about it.
+func (n *bnode[T]) spawn() subtree {
+	st := n.subtree()
+	go n.run()
+	return st
+}
+
+func (n *bnode[T]) run() {
+	for cm := range n.cmdchan {
+		switch cm.typ() {
+		case ctInsert:
+			n.insert(cm.(insertCmd[T]))
+
+		case ctContains:
+			n.contains(cm.(containsCmd[T]))
+
+		case ctDelete:
+			if n.delete(cm.(deleteCmd[T])) {
+				return
+			}
+
+		case ctExtractMin:
+			n.extractMin(cm.(extractMinCmd[T]))
+
+		case ctSplit:
+			n.split(cm.(splitCmd[T]))
+
+		case ctMerge:
+			n.merge(cm.(mergeCmd[T]))
+
+		case ctDrain:
+			dc := cm.(drainCmd[T])
+			dc.reschan <- drainResult[T]{keys: n.keys, children: n.children}
+			return
+
+		case ctPrint:
+			n.print(cm.(printCmd))
+
+		case ctClose:
+			n.close(cm.(closeCmd))
+			return
+
+		default:
+			panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", cm))
+		}
+	}
+}
+
+func (n *bnode[T]) subtree() subtree {
+	size := len(n.keys)
+	for _, child := range n.children {
+		size += child.size
+	}
+
+	return subtree{
+		ch:   n.cmdchan,
+		size: size,
+		keys: len(n.keys),
+	}
+}
+
+func (n *bnode[T]) leaf() bool {
+	return len(n.children) == 0
+}
+
+// least is the fewest values any node other than the root can hold.
+func (n *bnode[T]) least() int {
+	return (n.cfg.block - 1) / 2
+}
+
+// search finds val among the values in this node. It returns the index of val
+// if it's here, or of the child it would be under if not.
+func (n *bnode[T]) search(val T) (int, bool, error) {
+	lo, hi := 0, len(n.keys)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+
+		comparison, err := n.cfg.compare(val, n.keys[mid])
+		if err != nil {
+			return 0, false, err
+		}
+
+		switch {
+		case comparison == 0:
+			return mid, true, nil
+		case comparison < 0:
+			hi = mid
+		default:
+			lo = mid + 1
+		}
+	}
+
+	return lo, false, nil
+}
+
+func (n *bnode[T]) forward(child *subtree, c cmd) {
+	child.ch <- c
+	*child = <-n.ackchan
+}
+
+func (n *bnode[T]) insert(c insertCmd[T]) {
+	for {
+		i, found, err := n.search(c.val)
+		if err != nil {
+			c.ack <- n.subtree()
+			c.reschan <- Result{Err: err}
+			return
+		}
+
+		if found {
+			c.ack <- n.subtree()
+			c.reschan <- Result{}
+			return
+		}
+
+		if n.leaf() {
+			n.keys = slices.Insert(n.keys, i, c.val)
+			c.ack <- n.subtree()
+			c.reschan <- Result{Ok: true}
+			return
+		}
+
+		if n.children[i].keys < n.cfg.block {
+			parentack := c.ack
+			c.ack = n.ackchan
+			n.forward(&n.children[i], c)
+
+			parentack <- n.subtree()
+			return
+		}
+
+		// the middle value of the full child comes up here, so look again
+		n.splitChild(i, n.children[i].keys/2)
+	}
+}
+
+func (n *bnode[T]) contains(c containsCmd[T]) {
+	i, found, err := n.search(c.val)
+	if err != nil {
+		c.reschan <- Result{Err: err}
+		return
+	}
+
+	if found {
+		c.reschan <- Result{Ok: true}
+		return
+	}
+
+	if n.leaf() {
+		c.reschan <- Result{}
+		return
+	}
+
+	n.children[i].ch <- c
+}
+
+// delete returns true if this node has removed itself from the tree, which
+// only ever happens to the root once it has no values left.
+func (n *bnode[T]) delete(c deleteCmd[T]) bool {
+	parentack := c.ack
+	c.ack = n.ackchan
+
+	res := n.deleteStep(c)
+
+	st := n.subtree()
+	gone := len(n.keys) == 0
+	if gone {
+		// the root's last two children were merged, or its last value was
+		// deleted. Either way whatever is left is the new root.
+		st = subtree{}
+		if !n.leaf() {
+			st = n.children[0]
+		}
+	}
+
+	parentack <- st
+	if res != nil {
+		c.reschan <- *res
+	}
+
+	return gone
+}
+
+// deleteStep does the work of delete. It returns the result if this node is
+// the one answering, or nil if a child already has.
+func (n *bnode[T]) deleteStep(c deleteCmd[T]) *Result {
+	for {
+		i, found, err := n.search(c.val)
+		if err != nil {
+			return &Result{Err: err}
+		}
+
+		if n.leaf() {
+			if !found {
+				return &Result{}
+			}
+
+			n.keys = slices.Delete(n.keys, i, i+1)
+			return &Result{Ok: true}
+		}
+
+		if found {
+			// the value before or after this one takes its place, from
+			// whichever side can spare it
+			if n.children[i].keys > n.least() {
+				n.keys[i] = n.extract(i, true)
+				return &Result{Ok: true}
+			}
+
+			if n.children[i+1].keys > n.least() {
+				n.keys[i] = n.extract(i+1, false)
+				return &Result{Ok: true}
+			}
+
+			// neither can, so the value goes down into both sides merged
+			// together and gets deleted from there
+			n.mergeChildren(i)
+			n.forward(&n.children[i], c)
+			return nil
+		}
+
+		if n.children[i].keys > n.least() {
+			n.forward(&n.children[i], c)
+			return nil
+		}
+
+		n.fill(i)
+	}
+}
+
+// extractMin takes the smallest value (or the largest, if max is set) out of
+// this node's subtree. The parent has made sure this node can spare one.
+func (n *bnode[T]) extractMin(c extractMinCmd[T]) {
+	for {
+		if n.leaf() {
+			i := 0
+			if c.max {
+				i = len(n.keys) - 1
+			}
+
+			val := n.keys[i]
+			n.keys = slices.Delete(n.keys, i, i+1)
+
+			c.reschan <- subtreeMinResponse[T]{val: val, count: 1, rest: n.subtree()}
+			return
+		}
+
+		i := 0
+		if c.max {
+			i = len(n.children) - 1
+		}
+
+		if n.children[i].keys > n.least() {
+			val := n.extract(i, c.max)
+			c.reschan <- subtreeMinResponse[T]{val: val, count: 1, rest: n.subtree()}
+			return
+		}
+
+		n.fill(i)
+	}
+}
+
+// extract takes the smallest or largest value out of one of the children.
+func (n *bnode[T]) extract(i int, max bool) T {
+	reschan := make(chan subtreeMinResponse[T])
+	n.children[i].ch <- extractMinCmd[T]{reschan: reschan, max: max}
+
+	res := <-reschan
+	n.children[i] = res.rest
+	return res.val
+}
+
+// fill makes sure the child at i can spare a value, by merging it with the
+// node next to it and then, if that's too much for one node, splitting them
+// up again so the side that i was on has one more than the least.
+func (n *bnode[T]) fill(i int) {
+	lo := max(i-1, 0)
+	n.mergeChildren(lo)
+
+	merged := n.children[lo].keys
+	if merged <= n.cfg.block {
+		return
+	}
+
+	at := n.least() + 1
+	if lo != i {
+		at = merged - 1 - (n.least() + 1)
+	}
+
+	n.splitChild(lo, at)
+}
+
+// mergeChildren merges the children on either side of keys[i], along with
+// keys[i] itself, into the one on the left.
+func (n *bnode[T]) mergeChildren(i int) {
+	n.forward(&n.children[i], mergeCmd[T]{
+		sep:  n.keys[i],
+		from: n.children[i+1],
+		ack:  n.ackchan,
+	})
+
+	n.keys = slices.Delete(n.keys, i, i+1)
+	n.children = slices.Delete(n.children, i+1, i+2)
+}
+
+// splitChild splits the child at i and takes in the value that comes up out
+// of it.
+func (n *bnode[T]) splitChild(i, at int) {
+	median, left, right := split[T](n.children[i], at)
+
+	n.keys = slices.Insert(n.keys, i, median)
+	n.children[i] = left
+	n.children = slices.Insert(n.children, i+1, right)
+}
+
+// split has the node at st split itself at at.
+func split[T any](st subtree, at int) (T, subtree, subtree) {
+	reschan := make(chan splitResult[T])
+	st.ch <- splitCmd[T]{reschan: reschan, at: at}
+
+	res := <-reschan
+	return res.median, res.left, res.right
+}
+
+func (n *bnode[T]) split(c splitCmd[T]) {
+	right := newBNode(n.cfg, slices.Clone(n.keys[c.at+1:]), nil)
+	median := n.keys[c.at]
+	n.keys = slices.Clip(n.keys[:c.at])
+
+	if !n.leaf() {
+		right.children = slices.Clone(n.children[c.at+1:])
+		n.children = slices.Clip(n.children[:c.at+1])
+	}
+
+	c.reschan <- splitResult[T]{
+		median: median,
+		left:   n.subtree(),
+		right:  right.spawn(),
+	}
+}
+
+// merge takes in the separating value and everything the node to the right
+// holds, which stops once it has handed it all over.
+func (n *bnode[T]) merge(c mergeCmd[T]) {
+	reschan := make(chan drainResult[T])
+	c.from.ch <- drainCmd[T]{reschan: reschan}
+
+	res := <-reschan
+	n.keys = slices.Concat(n.keys, []T{c.sep}, res.keys)
+	n.children = slices.Concat(n.children, res.children)
+
+	c.ack <- n.subtree()
+}
+
+func (n *bnode[T]) print(c printCmd) {
+	childcmd := c
+	childcmd.reschan = make(chan struct{})
+	childcmd.level++
+
+	indent := bytes.Repeat([]byte(" "), c.level)
+
+	for i, key := range n.keys {
+		if !n.leaf() {
+			n.children[i].ch <- childcmd
+			<-childcmd.reschan
+		}
+
+		io.WriteString(c.w, fmt.Sprintf("%s%v\n", indent, key))
+	}
+
+	if !n.leaf() {
+		n.children[len(n.keys)].ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	c.reschan <- struct{}{}
+}
+
+func (n *bnode[T]) close(c closeCmd) {
+	childcmd := closeCmd{reschan: make(chan struct{})}
+
+	for _, child := range n.children {
+		child.ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	c.reschan <- struct{}{}
+}
@@ -0,0 +1,124 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+type boundKind int
+
+const (
+	floor       boundKind = iota // greatest value <= val
+	ceiling                      // smallest value >= val
+	predecessor                  // greatest value < val
+	successor                    // smallest value > val
+)
+
+// boundCmd looks for the closest value to val on one side of it. It walks down
+// the tree like a contains, and every node it passes that's on the right side
+// of val becomes the best candidate so far, since anything closer has to be
+// further down the path. Whoever is at the bottom of the path answers with
+// the best candidate, if there is one.
+type boundCmd[T any] struct {
+	reschan chan valueResult[T]
+	val     T
+	kind    boundKind
+	best    T
+	found   bool
+}
+
+func (c boundCmd[T]) typ() cmdType {
+	return ctBound
+}
+
+// Floor returns the greatest value in the tree that is less than or equal to
+// val. The bool is false if there isn't one.
+func (g *Goroutree[T]) Floor(val T) (T, bool, error) {
+	return g.bound(val, floor)
+}
+
+// Ceiling returns the smallest value in the tree that is greater than or equal
+// to val. The bool is false if there isn't one.
+func (g *Goroutree[T]) Ceiling(val T) (T, bool, error) {
+	return g.bound(val, ceiling)
+}
+
+// Predecessor returns the greatest value in the tree that is strictly less than
+// val. The bool is false if there isn't one.
+func (g *Goroutree[T]) Predecessor(val T) (T, bool, error) {
+	return g.bound(val, predecessor)
+}
+
+// Successor returns the smallest value in the tree that is strictly greater
+// than val. The bool is false if there isn't one.
+func (g *Goroutree[T]) Successor(val T) (T, bool, error) {
+	return g.bound(val, successor)
+}
+
+func (g *Goroutree[T]) bound(val T, kind boundKind) (T, bool, error) {
+	reschan := make(chan valueResult[T], 1)
+	res, err := request(context.Background(), g, boundCmd[T]{
+		reschan: reschan,
+		val:     val,
+		kind:    kind,
+	}, reschan)
+
+	if err == nil {
+		err = res.err
+	}
+
+	return res.val, res.ok, err
+}
+
+func (n *node[T]) bound(c boundCmd[T]) {
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		c.reschan <- valueResult[T]{err: err}
+		return
+	}
+
+	// an exact match is the answer when equal values count
+	if comparison == 0 && (c.kind == floor || c.kind == ceiling) {
+		c.reschan <- valueResult[T]{val: n.val, ok: true}
+		return
+	}
+
+	// Looking below val, this node is a candidate if it's smaller than val,
+	// and anything closer would be to its right. Otherwise the answer has to
+	// be to the left. Looking above val is the mirror image.
+	var next chan cmd
+
+	if c.kind == floor || c.kind == predecessor {
+		if comparison > 0 {
+			c.best, c.found = n.val, true
+			next = n.right.ch
+		} else {
+			next = n.left.ch
+		}
+	} else {
+		if comparison < 0 {
+			c.best, c.found = n.val, true
+			next = n.left.ch
+		} else {
+			next = n.right.ch
+		}
+	}
+
+	if next != nil {
+		next <- c
+		return
+	}
+
+	c.reschan <- valueResult[T]{val: c.best, ok: c.found}
+}
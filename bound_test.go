@@ -0,0 +1,74 @@
+package goroutree_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestBound(t *testing.T) {
+	ctx := context.Background()
+
+	g := goroutree.NewOrdered[int]()
+	defer g.Close()
+
+	// every even number from 0 to 28, balanced
+	for _, i := range balancedOrder {
+		g.InsertCtx(ctx, i*2)
+	}
+
+	type query func(int) (int, bool, error)
+
+	for _, tc := range []struct {
+		name  string
+		q     query
+		val   int
+		want  int
+		found bool
+	}{
+		{"FloorHit", g.Floor, 8, 8, true},
+		{"FloorBetween", g.Floor, 9, 8, true},
+		{"FloorBelowAll", g.Floor, -1, 0, false},
+		{"FloorAboveAll", g.Floor, 100, 28, true},
+		{"CeilingHit", g.Ceiling, 8, 8, true},
+		{"CeilingBetween", g.Ceiling, 9, 10, true},
+		{"CeilingBelowAll", g.Ceiling, -1, 0, true},
+		{"CeilingAboveAll", g.Ceiling, 29, 0, false},
+		{"PredecessorHit", g.Predecessor, 8, 6, true},
+		{"PredecessorBetween", g.Predecessor, 15, 14, true},
+		{"PredecessorMin", g.Predecessor, 0, 0, false},
+		{"SuccessorHit", g.Successor, 8, 10, true},
+		{"SuccessorBetween", g.Successor, 13, 14, true},
+		{"SuccessorMax", g.Successor, 28, 0, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v, ok, err := tc.q(tc.val)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if ok != tc.found || (ok && v != tc.want) {
+				t.Fatalf("Expected %d, %v for %d, got %d, %v", tc.want, tc.found, tc.val, v, ok)
+			}
+		})
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		e := goroutree.NewOrdered[int]()
+		defer e.Close()
+
+		if _, ok, err := e.Floor(5); ok || err != nil {
+			t.Fatalf("Expected nothing from floor, got %v, %v", ok, err)
+		}
+	})
+	t.Run("CompareError", func(t *testing.T) {
+		c := goroutree.New()
+		defer c.Close()
+
+		c.InsertCtx(ctx, Str("m"))
+
+		if _, _, err := c.Successor(goroutree.Int(4)); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from successor, got %v", err)
+		}
+	})
+}
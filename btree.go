@@ -0,0 +1,206 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// BTree is a set made of node goroutines like a Goroutree, except that each
+// node holds a sorted block of values instead of just one, with a child
+// between each pair of them. That takes far fewer goroutines and channel hops
+// for the same number of values. Every leaf is at the same depth, so the tree
+// is always balanced.
+//
+// It takes the same commands as a Goroutree for Insert, Contains and Delete
+// and gives the same answers, and it orders its values the same ways.
+type BTree[T any] struct {
+	tree *Goroutree[T]
+}
+
+// NewBTree creates a new empty BTree of Comparer values, ordered by their
+// Compare method, where each node holds up to b values. b is raised to 3 if
+// it's any smaller.
+func NewBTree(b int) *BTree[Comparer] {
+	return newBTree(b, func(a, b Comparer) (int, error) {
+		return a.Compare(b)
+	})
+}
+
+// NewBTreeOrdered creates a new empty BTree of values that can be ordered with
+// the < operator, where each node holds up to b values.
+func NewBTreeOrdered[T cmp.Ordered](b int) *BTree[T] {
+	return NewBTreeFunc(b, cmp.Compare[T])
+}
+
+// NewBTreeFunc creates a new empty BTree ordered by the given function, which
+// follows the same rules as the one passed to NewFunc, where each node holds up
+// to b values.
+func NewBTreeFunc[T any](b int, compare func(a, b T) int) *BTree[T] {
+	return newBTree(b, func(a, b T) (int, error) {
+		return compare(a, b), nil
+	})
+}
+
+func newBTree[T any](b int, compare func(a, b T) (int, error)) *BTree[T] {
+	cfg := &config[T]{
+		compare: compare,
+		block:   max(b, 3),
+	}
+
+	tree := &Goroutree[T]{
+		cmdchan: make(chan cmd),
+		done:    make(chan struct{}),
+		cfg:     cfg,
+	}
+	go btreeManager(tree.cmdchan, cfg, &tree.queued)
+
+	return &BTree[T]{tree: tree}
+}
+
+// Insert adds a value to the set. See Goroutree.Insert.
+func (t *BTree[T]) Insert(reschan chan Result, val T) error {
+	return t.tree.Insert(reschan, val)
+}
+
+// Contains checks whether a value is in the set. See Goroutree.Contains.
+func (t *BTree[T]) Contains(reschan chan Result, val T) error {
+	return t.tree.Contains(reschan, val)
+}
+
+// Delete takes a value out of the set. See Goroutree.Delete.
+func (t *BTree[T]) Delete(reschan chan Result, val T) error {
+	return t.tree.Delete(reschan, val)
+}
+
+// InsertCtx adds a value to the set and waits for the answer. See
+// Goroutree.InsertCtx.
+func (t *BTree[T]) InsertCtx(ctx context.Context, val T) (bool, error) {
+	return t.tree.InsertCtx(ctx, val)
+}
+
+// ContainsCtx checks whether a value is in the set and waits for the answer.
+// See Goroutree.ContainsCtx.
+func (t *BTree[T]) ContainsCtx(ctx context.Context, val T) (bool, error) {
+	return t.tree.ContainsCtx(ctx, val)
+}
+
+// DeleteCtx takes a value out of the set and waits for the answer. See
+// Goroutree.DeleteCtx.
+func (t *BTree[T]) DeleteCtx(ctx context.Context, val T) (bool, error) {
+	return t.tree.DeleteCtx(ctx, val)
+}
+
+// Len returns the number of values in the set.
+func (t *BTree[T]) Len() (int, error) {
+	return t.tree.Len()
+}
+
+// Print prints out the tree the same way Goroutree.Print does: every value on
+// its own line, in order, indented by the depth of the node holding it.
+func (t *BTree[T]) Print(reschan chan struct{}, w io.Writer) error {
+	return t.tree.Print(reschan, w)
+}
+
+// Close shuts down every node in the tree. See Goroutree.Close.
+func (t *BTree[T]) Close() error {
+	return t.tree.Close()
+}
+
+// btreeManager does the same job for a BTree that manager does for a
+// Goroutree.
+func btreeManager[T any](main chan cmd, cfg *config[T], queued *atomic.Int64) {
+	var root subtree
+	ackchan := make(chan subtree)
+
+	for c := range main {
+		queued.Add(-1)
+
+		switch c.typ() {
+		case ctInsert:
+			ic := c.(insertCmd[T])
+			if root.ch == nil {
+				root = newBNode(cfg, []T{ic.val}, nil).spawn()
+				ic.reschan <- Result{Ok: true}
+				continue
+			}
+
+			// nodes are split on the way down before they can overflow,
+			// and the root is no different. Splitting it is the only way
+			// the tree gets deeper.
+			if root.keys == cfg.block {
+				median, left, right := split[T](root, root.keys/2)
+				root = newBNode(cfg, []T{median}, []subtree{left, right}).spawn()
+			}
+
+			ic.ack = ackchan
+			root.ch <- ic
+			root = <-ackchan
+
+		case ctContains:
+			if root.ch == nil {
+				cc := c.(containsCmd[T])
+				cc.reschan <- Result{}
+				continue
+			}
+
+			root.ch <- c
+
+		case ctDelete:
+			dc := c.(deleteCmd[T])
+			if root.ch == nil {
+				dc.reschan <- Result{}
+				continue
+			}
+
+			dc.ack = ackchan
+			root.ch <- dc
+			root = <-ackchan
+
+		case ctPrint:
+			if root.ch == nil {
+				pc := c.(printCmd)
+				pc.w.Write([]byte("\n"))
+				pc.reschan <- struct{}{}
+				continue
+			}
+
+			root.ch <- c
+
+		case ctClose:
+			cc := c.(closeCmd)
+
+			if root.ch != nil {
+				reschan := make(chan struct{})
+				root.ch <- closeCmd{reschan: reschan}
+				<-reschan
+			}
+
+			cc.reschan <- struct{}{}
+			return
+
+		case ctLen:
+			lc := c.(lenCmd)
+			lc.reschan <- root.size
+
+		default:
+			panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", c))
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package goroutree_test
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// checkBTree fails unless the values in the tree are in order and every leaf
+// is at the same depth. Print goes in order, so a value any shallower than the
+// deepest ones belongs to a node with children, and has to have something
+// deeper on both sides of it.
+func checkBTree(t *testing.T, bt *goroutree.BTree[int], expected int) {
+	t.Helper()
+
+	type line struct{ val, depth int }
+	var lines []line
+	deepest := 0
+
+	for _, l := range strings.Split(printed(t, bt), "\n") {
+		if l == "" {
+			continue
+		}
+
+		trimmed := strings.TrimLeft(l, " ")
+		v, err := strconv.Atoi(trimmed)
+		if err != nil {
+			t.Fatalf("Expected an int in print output, got %q", l)
+		}
+
+		lines = append(lines, line{v, len(l) - len(trimmed)})
+		deepest = max(deepest, len(l)-len(trimmed))
+	}
+
+	if len(lines) != expected {
+		t.Fatalf("Expected %d values, got %d", expected, len(lines))
+	}
+
+	for i, l := range lines {
+		if i > 0 && lines[i-1].val >= l.val {
+			t.Fatalf("Expected values in order, got %d before %d", lines[i-1].val, l.val)
+		}
+
+		if l.depth == deepest {
+			continue
+		}
+
+		if i == 0 || i == len(lines)-1 || lines[i-1].depth <= l.depth || lines[i+1].depth <= l.depth {
+			t.Fatalf("Expected %d at depth %d to have children on both sides", l.val, l.depth)
+		}
+	}
+
+	checkLen(t, bt, expected)
+}
+
+func TestBTree(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("InsertContainsDelete", func(t *testing.T) {
+		bt := goroutree.NewBTreeOrdered[int](3)
+		defer bt.Close()
+
+		if ok, err := bt.InsertCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from inserting, got %v, %v", ok, err)
+		}
+		if ok, err := bt.InsertCtx(ctx, 4); ok || err != nil {
+			t.Fatalf("Expected a false result from inserting duplicate, got %v, %v", ok, err)
+		}
+		if ok, err := bt.ContainsCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from contains, got %v, %v", ok, err)
+		}
+		if ok, err := bt.DeleteCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from delete, got %v, %v", ok, err)
+		}
+		if ok, err := bt.DeleteCtx(ctx, 4); ok || err != nil {
+			t.Fatalf("Expected a false result from deleting again, got %v, %v", ok, err)
+		}
+		if ok, err := bt.ContainsCtx(ctx, 4); ok || err != nil {
+			t.Fatalf("Expected a false result from contains, got %v, %v", ok, err)
+		}
+		checkLen(t, bt, 0)
+	})
+	t.Run("Split", func(t *testing.T) {
+		bt := goroutree.NewBTreeOrdered[int](3)
+		defer bt.Close()
+
+		// the fourth value splits the full root, so 2 goes up on its own
+		for i := 1; i <= 4; i++ {
+			bt.InsertCtx(ctx, i)
+		}
+
+		if got := printed(t, bt); got != " 1\n2\n 3\n 4\n" {
+			t.Fatalf("Expected 2 over two leaves, got:\n%s", got)
+		}
+	})
+	t.Run("Depth", func(t *testing.T) {
+		bt := goroutree.NewBTreeOrdered[int](15)
+		defer bt.Close()
+
+		for i := 0; i < 1000; i++ {
+			bt.InsertCtx(ctx, i)
+		}
+
+		// every node but the root holds at least 7 values, so 1000 fit in 4
+		// levels
+		checkBTree(t, bt, 1000)
+		if got := printed(t, bt); strings.Contains(got, "\n    ") {
+			t.Fatalf("Expected at most 4 levels, got:\n%s", got)
+		}
+	})
+	t.Run("Random", func(t *testing.T) {
+		for _, b := range []int{3, 4, 5, 8} {
+			bt := goroutree.NewBTreeOrdered[int](b)
+
+			rng := rand.New(rand.NewSource(int64(b)))
+			model := map[int]bool{}
+
+			for i := 0; i < 2000; i++ {
+				v := rng.Intn(300)
+
+				switch rng.Intn(3) {
+				case 0:
+					if ok, _ := bt.InsertCtx(ctx, v); ok == model[v] {
+						t.Fatalf("Expected insert %d to return %v with b=%d", v, !model[v], b)
+					}
+					model[v] = true
+				case 1:
+					if ok, _ := bt.DeleteCtx(ctx, v); ok != model[v] {
+						t.Fatalf("Expected delete %d to return %v with b=%d", v, model[v], b)
+					}
+					delete(model, v)
+				case 2:
+					if ok, _ := bt.ContainsCtx(ctx, v); ok != model[v] {
+						t.Fatalf("Expected contains %d to return %v with b=%d", v, model[v], b)
+					}
+				}
+
+				if i%50 == 0 {
+					checkBTree(t, bt, len(model))
+				}
+			}
+
+			// emptying it out shrinks it all the way back down
+			for v := range model {
+				if ok, _ := bt.DeleteCtx(ctx, v); !ok {
+					t.Fatalf("Expected delete %d to return true with b=%d", v, b)
+				}
+			}
+			checkBTree(t, bt, 0)
+
+			bt.Close()
+		}
+	})
+	t.Run("Comparer", func(t *testing.T) {
+		bt := goroutree.NewBTree(4)
+		defer bt.Close()
+
+		for i := 0; i < 20; i++ {
+			bt.InsertCtx(ctx, goroutree.Int(i))
+		}
+
+		if ok, err := bt.ContainsCtx(ctx, goroutree.Int(7)); !ok || err != nil {
+			t.Fatalf("Expected a true result from contains, got %v, %v", ok, err)
+		}
+		if _, err := bt.InsertCtx(ctx, Str("m")); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from inserting, got %v", err)
+		}
+		if _, err := bt.DeleteCtx(ctx, Str("m")); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from deleting, got %v", err)
+		}
+		checkLen(t, bt, 20)
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		bt := goroutree.NewBTreeOrdered[int](4)
+		defer bt.Close()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < 100; i++ {
+					v := i*8 + w
+					if ok, err := bt.InsertCtx(ctx, v); !ok || err != nil {
+						t.Errorf("Expected a true result from inserting %d, got %v, %v", v, ok, err)
+						return
+					}
+					if ok, _ := bt.ContainsCtx(ctx, v); !ok {
+						t.Errorf("Expected %d to be found right after inserting it", v)
+						return
+					}
+					if i%2 == 1 {
+						if ok, _ := bt.DeleteCtx(ctx, v); !ok {
+							t.Errorf("Expected a true result from deleting %d", v)
+							return
+						}
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		checkBTree(t, bt, 400)
+	})
+	t.Run("Closed", func(t *testing.T) {
+		bt := goroutree.NewBTreeOrdered[int](4)
+		bt.Close()
+
+		if _, err := bt.InsertCtx(ctx, 4); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from inserting, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "slices"
+
+// builder spawns a whole tree of nodes from scratch. Every node is set up,
+// children first, before its goroutine starts, so nothing has to be sent
+// anywhere while it's being built.
+type builder[T any] struct {
+	cfg     *config[T]
+	entries []entry[T]
+
+	// the priority of each entry, for a Treap
+	prios []int64
+}
+
+// parallelCutoff is the smallest number of entries the builder hands off to
+// another goroutine. The two sides of a subtree don't share anything, so they
+// can be built at the same time, but below this it's quicker to just build
+// them one after the other.
+const parallelCutoff = 1024
+
+// build spawns a tree holding entries, which must be sorted and hold no value
+// twice, and returns its root. The tree is as balanced as it can be, in a shape
+// that already follows the rules of whatever balancing the tree does.
+func build[T any](entries []entry[T], cfg *config[T]) subtree {
+	b := &builder[T]{cfg: cfg, entries: entries}
+
+	switch cfg.balance {
+	case RedBlack:
+		bh := 0
+		for 1<<(bh+1)-1 <= len(entries) {
+			bh++
+		}
+		return b.redBlack(0, len(entries), bh)
+
+	case Treap:
+		b.treapPriorities()
+	}
+
+	return b.midpoint(0, len(entries))
+}
+
+// node sets up the node for one entry, without starting it.
+func (b *builder[T]) node(i int) *node[T] {
+	e := b.entries[i]
+
+	n := newNode(e.val, e.payload, b.cfg)
+	n.count = e.count
+	n.red = false
+	if b.prios != nil {
+		n.prio = b.prios[i]
+	}
+
+	return n
+}
+
+// midpoint builds entries[lo:hi] with the middle one on top, so the two sides
+// never differ in size by more than one.
+func (b *builder[T]) midpoint(lo, hi int) subtree {
+	if lo == hi {
+		return subtree{}
+	}
+
+	mid := lo + (hi-lo)/2
+	n := b.node(mid)
+	n.left, n.right = b.both(hi-lo, func() subtree {
+		return b.midpoint(lo, mid)
+	}, func() subtree {
+		return b.midpoint(mid+1, hi)
+	})
+
+	return n.spawn()
+}
+
+// redBlack builds entries[lo:hi] as a 2-3 tree where every path down has bh
+// nodes, laid out as a left-leaning red-black tree: a 2-node is a single black
+// node and a 3-node is a black node with a red left child. Between 2^bh-1 and
+// 3^bh-1 entries fit.
+func (b *builder[T]) redBlack(lo, hi, bh int) subtree {
+	count := hi - lo
+	if count == 0 {
+		return subtree{}
+	}
+
+	// the most a subtree one level down can hold
+	most := 1
+	for i := 1; i < bh; i++ {
+		most *= 3
+	}
+	most--
+
+	if count-1 <= 2*most {
+		mid := lo + (count-1)/2
+		n := b.node(mid)
+		n.left, n.right = b.both(count, func() subtree {
+			return b.redBlack(lo, mid, bh-1)
+		}, func() subtree {
+			return b.redBlack(mid+1, hi, bh-1)
+		})
+
+		return n.spawn()
+	}
+
+	// too many for a 2-node, so split what's left three ways around a
+	// 3-node
+	rest := count - 2
+	first := rest / 3
+	second := (rest - first) / 2
+
+	x := lo + first
+	y := x + 1 + second
+
+	n := b.node(y)
+	n.left, n.right = b.both(count, func() subtree {
+		red := b.node(x)
+		red.red = true
+		red.left, red.right = b.both(y-lo, func() subtree {
+			return b.redBlack(lo, x, bh-1)
+		}, func() subtree {
+			return b.redBlack(x+1, y, bh-1)
+		})
+
+		return red.spawn()
+	}, func() subtree {
+		return b.redBlack(y+1, hi, bh-1)
+	})
+
+	return n.spawn()
+}
+
+// both builds two subtrees that hold count entries between them, the first on
+// its own goroutine if there are enough of them to make that worth it.
+func (b *builder[T]) both(count int, first, second func() subtree) (subtree, subtree) {
+	if count < parallelCutoff {
+		return first(), second()
+	}
+
+	ch := make(chan subtree, 1)
+	go func() {
+		ch <- first()
+	}()
+
+	st := second()
+	return <-ch, st
+}
+
+// treapPriorities draws a fresh priority for every entry and hands them out so
+// the highest ones go to the nodes nearest the top of the tree midpoint builds.
+func (b *builder[T]) treapPriorities() {
+	depths := make([]int, len(b.entries))
+
+	var walk func(lo, hi, depth int)
+	walk = func(lo, hi, depth int) {
+		if lo == hi {
+			return
+		}
+
+		mid := lo + (hi-lo)/2
+		depths[mid] = depth
+		walk(lo, mid, depth+1)
+		walk(mid+1, hi, depth+1)
+	}
+	walk(0, len(b.entries), 0)
+
+	prios := make([]int64, len(b.entries))
+	for i := range prios {
+		prios[i] = b.cfg.priority()
+	}
+	slices.Sort(prios)
+	slices.Reverse(prios)
+
+	order := make([]int, len(b.entries))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, c int) int {
+		return depths[a] - depths[c]
+	})
+
+	b.prios = make([]int64, len(b.entries))
+	for i, idx := range order {
+		b.prios[idx] = prios[i]
+	}
+}
@@ -0,0 +1,104 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"context"
+	"math/rand"
+)
+
+// cloneCmd is handled by the manager, which turns it into a copyCmd sent to
+// the root and starts a new manager for the copy.
+type cloneCmd[T any] struct {
+	reschan chan *Goroutree[T]
+}
+
+func (c cloneCmd[T]) typ() cmdType {
+	return ctClone
+}
+
+// copyCmd has a node copy itself and everything under it into new nodes
+// belonging to cfg. Like ctPrint, each node holds on to the command until its
+// children have copied themselves, so the whole subtree stays still while
+// it's being copied.
+type copyCmd[T any] struct {
+	reschan chan subtree
+	cfg     *config[T]
+}
+
+func (c copyCmd[T]) typ() cmdType {
+	return ctCopy
+}
+
+// Clone makes a copy of the tree with the same values in the same shape, made
+// of new node goroutines with their own manager, so nothing done to one of the
+// trees shows up in the other. Every node holds still while its subtree is
+// being copied, the same as for Print, so the copy is a snapshot of the tree
+// at one moment. Payloads stored by a Map are copied as they are, so a pointer
+// in one is shared by both.
+func (g *Goroutree[T]) Clone() (*Goroutree[T], error) {
+	reschan := make(chan *Goroutree[T], 1)
+	return request(context.Background(), g, cloneCmd[T]{reschan: reschan}, reschan)
+}
+
+// Clone makes a copy of the map. See Goroutree.Clone.
+func (m *Map[K, V]) Clone() (*Map[K, V], error) {
+	tree, err := m.tree.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Map[K, V]{tree: tree}, nil
+}
+
+// clone makes a config for a copy of the tree. A Treap gets a random source of
+// its own, seeded from this one, so a seeded tree still gives seeded copies.
+func (cfg *config[T]) clone() *config[T] {
+	c := &config[T]{
+		compare: cfg.compare,
+		options: cfg.options,
+		block:   cfg.block,
+	}
+
+	if cfg.rng != nil {
+		c.rng = rand.New(rand.NewSource(cfg.priority()))
+	}
+
+	return c
+}
+
+func (n *node[T]) copy(c copyCmd[T]) {
+	cp := newNode(n.val, n.payload, c.cfg)
+	cp.count = n.count
+	cp.red = n.red
+	cp.prio = n.prio
+
+	childcmd := copyCmd[T]{
+		reschan: make(chan subtree),
+		cfg:     c.cfg,
+	}
+
+	if n.left.ch != nil {
+		n.left.ch <- childcmd
+		cp.left = <-childcmd.reschan
+	}
+
+	if n.right.ch != nil {
+		n.right.ch <- childcmd
+		cp.right = <-childcmd.reschan
+	}
+
+	c.reschan <- cp.spawn()
+}
@@ -0,0 +1,146 @@
+package goroutree_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestClone(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		c, err := g.Clone()
+		if err != nil {
+			t.Fatalf("Expected no error from clone, got %v", err)
+		}
+		defer c.Close()
+
+		checkLen(t, c, 0)
+		c.InsertCtx(ctx, 1)
+		checkLen(t, g, 0)
+	})
+	t.Run("Shape", func(t *testing.T) {
+		for _, opts := range [][]goroutree.Option{
+			nil,
+			{goroutree.WithBalance(goroutree.AVL)},
+			{goroutree.WithBalance(goroutree.RedBlack)},
+			{goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(1)},
+			{goroutree.Multiset()},
+		} {
+			g := goroutree.NewOrdered[int](opts...)
+
+			for _, i := range []int{8, 3, 12, 1, 5, 3, 10, 14, 7, 2} {
+				g.InsertCtx(ctx, i)
+			}
+
+			c, err := g.Clone()
+			if err != nil {
+				t.Fatalf("Expected no error from clone, got %v", err)
+			}
+
+			// Print shows colours and counts as well, so they come along too
+			if got, expected := printed(t, c), printed(t, g); got != expected {
+				t.Fatalf("Expected the same tree:\n%s\ngot:\n%s", expected, got)
+			}
+
+			g.Close()
+			c.Close()
+		}
+	})
+	t.Run("Independent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for i := 0; i < 10; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		c, _ := g.Clone()
+
+		g.DeleteCtx(ctx, 3)
+		g.InsertCtx(ctx, 20)
+		c.DeleteCtx(ctx, 7)
+
+		checkOrder(t, g, []int{0, 1, 2, 4, 5, 6, 7, 8, 9, 20})
+		checkOrder(t, c, []int{0, 1, 2, 3, 4, 5, 6, 8, 9})
+		checkRB(t, c)
+
+		// closing the copy leaves the original running
+		c.Close()
+		if ok, err := g.ContainsCtx(ctx, 7); !ok || err != nil {
+			t.Fatalf("Expected a true result from contains, got %v, %v", ok, err)
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < 100; i++ {
+					g.InsertCtx(ctx, i*4+w)
+				}
+			}(w)
+		}
+
+		// each copy is a snapshot from some point in the middle of all that,
+		// so whatever it has, it agrees with itself about it
+		for i := 0; i < 10; i++ {
+			c, err := g.Clone()
+			if err != nil {
+				t.Fatalf("Expected no error from clone, got %v", err)
+			}
+
+			vals := slices.Collect(c.All())
+			if !slices.IsSorted(vals) {
+				t.Fatalf("Expected the copy to be in order, got %v", vals)
+			}
+			checkLen(t, c, len(vals))
+
+			c.Close()
+		}
+
+		wg.Wait()
+	})
+	t.Run("Map", func(t *testing.T) {
+		m := goroutree.NewMap[int, string]()
+		defer m.Close()
+
+		m.Put(1, "one")
+		m.Put(2, "two")
+
+		c, err := m.Clone()
+		if err != nil {
+			t.Fatalf("Expected no error from clone, got %v", err)
+		}
+		defer c.Close()
+
+		m.Put(1, "uno")
+
+		if v, ok, _ := c.Get(1); v != "one" || !ok {
+			t.Fatalf("Expected the old value in the copy, got %q, %v", v, ok)
+		}
+		if v, ok, _ := m.Get(1); v != "uno" || !ok {
+			t.Fatalf("Expected the new value in the original, got %q, %v", v, ok)
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, err := g.Clone(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from clone, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package goroutree_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// blockingWriter holds up a Print until it is released, which keeps the whole
+// tree busy in the meantime.
+type blockingWriter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+
+	<-w.release
+	return len(p), nil
+}
+
+func TestCtx(t *testing.T) {
+	t.Run("InsertContainsDelete", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		ctx := context.Background()
+
+		if ok, err := g.InsertCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from inserting, got %v, %v", ok, err)
+		}
+		if ok, err := g.InsertCtx(ctx, 4); ok || err != nil {
+			t.Fatalf("Expected a false result from inserting duplicate, got %v, %v", ok, err)
+		}
+		if ok, err := g.ContainsCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from contains, got %v, %v", ok, err)
+		}
+		if ok, err := g.DeleteCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from delete, got %v, %v", ok, err)
+		}
+		if ok, err := g.ContainsCtx(ctx, 4); ok || err != nil {
+			t.Fatalf("Expected a false result from contains, got %v, %v", ok, err)
+		}
+	})
+	t.Run("CompareError", func(t *testing.T) {
+		g := goroutree.New()
+		ctx := context.Background()
+
+		g.InsertCtx(ctx, Str("m"))
+
+		if _, err := g.ContainsCtx(ctx, goroutree.Int(4)); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from contains, got %v", err)
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, err := g.InsertCtx(context.Background(), 4); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from inserting, got %v", err)
+		}
+	})
+	t.Run("Cancelled", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.InsertCtx(context.Background(), 4)
+
+		w := &blockingWriter{
+			started: make(chan struct{}, 1),
+			release: make(chan struct{}),
+		}
+
+		structreschan := make(chan struct{})
+		g.Print(structreschan, w)
+		<-w.started
+
+		// the root is stuck printing, so this can't be answered in time
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := g.ContainsCtx(ctx, 4); err != context.DeadlineExceeded {
+			t.Fatalf("Expected DeadlineExceeded from contains, got %v", err)
+		}
+
+		close(w.release)
+		<-structreschan
+
+		// the late reply to the abandoned call must not have wedged the root
+		ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if ok, err := g.ContainsCtx(ctx, 4); !ok || err != nil {
+			t.Fatalf("Expected a true result from contains, got %v, %v", ok, err)
+		}
+	})
+}
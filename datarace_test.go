@@ -8,7 +8,7 @@ import (
 
 func TestConcurrent(t *testing.T) {
 	g := goroutree.New()
-	boolreschan := make(chan bool)
+	boolreschan := make(chan goroutree.Result)
 	var vals map[goroutree.Int]bool = make(map[goroutree.Int]bool)
 
 	for i := -100000; i <= 100000; i++ {
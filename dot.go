@@ -0,0 +1,167 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// dotCmd writes a subtree out as part of a Graphviz graph. It goes down the
+// tree the same way ctPrint does, so nothing changes while it's being written.
+// Each node is numbered in the order it's reached, starting from id, and
+// sends back the next number nobody has used yet.
+type dotCmd struct {
+	reschan chan int
+	w       io.Writer
+	id      int
+	stats   bool
+}
+
+func (c dotCmd) typ() cmdType {
+	return ctDOT
+}
+
+// DOTOption changes what WriteDOT puts in the graph.
+type DOTOption func(*dotOptions)
+
+type dotOptions struct {
+	stats bool
+}
+
+// DOTStats labels every node in the graph with the size of its subtree, and
+// the manager with how many commands are waiting for it to take them.
+//
+// There's no count for the nodes themselves because they never have anything
+// waiting: a node only ever hears from its parent, one command at a time, and
+// while the graph is being written every parent is busy with that. Anything
+// held up is held up at the manager.
+func DOTStats() DOTOption {
+	return func(o *dotOptions) {
+		o.stats = true
+	}
+}
+
+// WriteDOT writes the tree out as a Graphviz digraph, with the manager at the
+// top, one node per node goroutine and an edge to each child labelled L or R.
+// Like Print, it holds every node still until the whole graph is written. The
+// values are written the same way Print writes them, and in a RedBlack tree
+// the red nodes are drawn in red. It returns the first error from writing to
+// w, if there is one.
+func (g *Goroutree[T]) WriteDOT(w io.Writer, opts ...DOTOption) error {
+	var o dotOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ew := &errWriter{w: w}
+	reschan := make(chan int, 1)
+
+	_, err := request(context.Background(), g, dotCmd{
+		reschan: reschan,
+		w:       ew,
+		stats:   o.stats,
+	}, reschan)
+	if err != nil {
+		return err
+	}
+
+	return ew.err
+}
+
+// errWriter holds on to the first error from writing, and doesn't write
+// anything after that.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+
+	n, err := ew.w.Write(p)
+	ew.err = err
+	return n, err
+}
+
+// dotEscape makes s safe to put between double quotes in a DOT file.
+var dotEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// manager writes the start and end of the graph around the tree, for a manager
+// with queued commands still waiting for it.
+func (c dotCmd) manager(root subtree, queued int64) {
+	io.WriteString(c.w, "digraph goroutree {\n")
+
+	label := "manager"
+	if c.stats {
+		label += fmt.Sprintf(`\nqueued %d`, queued)
+	}
+	fmt.Fprintf(c.w, "\tmanager [shape=box, label=\"%s\"];\n", label)
+
+	next := 0
+	if root.ch != nil {
+		io.WriteString(c.w, "\tmanager -> n0;\n")
+
+		childcmd := c
+		childcmd.reschan = make(chan int)
+		root.ch <- childcmd
+		next = <-childcmd.reschan
+	}
+
+	io.WriteString(c.w, "}\n")
+	c.reschan <- next
+}
+
+func (n *node[T]) dot(c dotCmd) {
+	label := dotEscape.Replace(fmt.Sprintf("%v", n.val))
+	if n.cfg.multiset {
+		label += fmt.Sprintf(" x%d", n.count)
+	}
+	if c.stats {
+		label += fmt.Sprintf(`\nsize %d`, n.subtree().size)
+	}
+
+	attrs := fmt.Sprintf("label=\"%s\"", label)
+	if n.cfg.balance == RedBlack && n.red {
+		attrs += ", color=red"
+	}
+	fmt.Fprintf(c.w, "\tn%d [%s];\n", c.id, attrs)
+
+	childcmd := c
+	childcmd.reschan = make(chan int)
+	childcmd.id = c.id + 1
+
+	for _, side := range []struct {
+		child subtree
+		name  string
+	}{
+		{n.left, "L"},
+		{n.right, "R"},
+	} {
+		if side.child.ch == nil {
+			continue
+		}
+
+		fmt.Fprintf(c.w, "\tn%d -> n%d [label=%s];\n", c.id, childcmd.id, side.name)
+		side.child.ch <- childcmd
+		childcmd.id = <-childcmd.reschan
+	}
+
+	c.reschan <- childcmd.id
+}
@@ -0,0 +1,133 @@
+package goroutree_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteDOT(t *testing.T) {
+	ctx := context.Background()
+
+	dot := func(t *testing.T, g interface {
+		WriteDOT(w io.Writer, opts ...goroutree.DOTOption) error
+	}, opts ...goroutree.DOTOption) string {
+		t.Helper()
+
+		buf := &bytes.Buffer{}
+		if err := g.WriteDOT(buf, opts...); err != nil {
+			t.Fatalf("Expected no error from writing, got %v", err)
+		}
+
+		return buf.String()
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		expected := "digraph goroutree {\n\tmanager [shape=box, label=\"manager\"];\n}\n"
+		if got := dot(t, g); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Shape", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{2, 1, 4, 3} {
+			g.InsertCtx(ctx, i)
+		}
+
+		expected := `digraph goroutree {
+	manager [shape=box, label="manager"];
+	manager -> n0;
+	n0 [label="2"];
+	n0 -> n1 [label=L];
+	n1 [label="1"];
+	n0 -> n2 [label=R];
+	n2 [label="4"];
+	n2 -> n3 [label=L];
+	n3 [label="3"];
+}
+`
+		if got := dot(t, g); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Stats", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{2, 1, 2} {
+			g.InsertCtx(ctx, i)
+		}
+
+		expected := `digraph goroutree {
+	manager [shape=box, label="manager\nqueued 0"];
+	manager -> n0;
+	n0 [label="2 x2\nsize 3"];
+	n0 -> n1 [label=L];
+	n1 [label="1 x1\nsize 1"];
+}
+`
+		if got := dot(t, g, goroutree.DOTStats()); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("RedBlack", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		g.InsertCtx(ctx, 2)
+		g.InsertCtx(ctx, 1)
+
+		if got := dot(t, g); !strings.Contains(got, "n1 [label=\"1\", color=red];") {
+			t.Fatalf("Expected 1 to be red, got:\n%s", got)
+		}
+	})
+	t.Run("Escaping", func(t *testing.T) {
+		g := goroutree.NewOrdered[string]()
+		defer g.Close()
+
+		g.InsertCtx(ctx, "say \"hi\"\\\n")
+
+		if got := dot(t, g); !strings.Contains(got, `n0 [label="say \"hi\"\\\n"];`) {
+			t.Fatalf("Expected the label to be escaped, got:\n%s", got)
+		}
+	})
+	t.Run("WriteError", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		g.InsertCtx(ctx, 1)
+
+		if err := g.WriteDOT(failingWriter{}); err == nil || err.Error() != "disk full" {
+			t.Fatalf("Expected the error from writing, got %v", err)
+		}
+
+		// the tree carries on as normal afterwards
+		if ok, _ := g.ContainsCtx(ctx, 1); !ok {
+			t.Fatal("Expected a true result from contains")
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if err := g.WriteDOT(&bytes.Buffer{}); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from writing, got %v", err)
+		}
+	})
+}
@@ -15,11 +15,20 @@
 package goroutree
 
 import (
-	"bytes"
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 )
 
+// ErrClosed is returned by any operation on a Goroutree after Close has been
+// called on it.
+var ErrClosed = errors.New("goroutree: tree is closed")
+
 ///////////////////////
 // Command definitions
 ///////////////////////
@@ -32,8 +41,28 @@ const (
 	ctContains
 	ctDelete
 	ctPrint
-	ctNewChild
 	ctExtractMin
+	ctClose
+	ctLen
+	ctPeek
+	ctPop
+	ctWalk
+	ctBound
+	ctRank
+	ctSelect
+	ctRotate
+	ctPivot
+	ctFlip
+	ctRebalance
+	ctSplit
+	ctMerge
+	ctDrain
+	ctBatch
+	ctClone
+	ctCopy
+	ctDOT
+	ctJSON
+	ctReplace
 )
 
 func (ct cmdType) String() string {
@@ -48,44 +77,118 @@ func (ct cmdType) String() string {
 		return "ctDelete"
 	case ctPrint:
 		return "ctPrint"
-	case ctNewChild:
-		return "ctNewChild"
 	case ctExtractMin:
 		return "ctExtractMin"
+	case ctClose:
+		return "ctClose"
+	case ctLen:
+		return "ctLen"
+	case ctPeek:
+		return "ctPeek"
+	case ctPop:
+		return "ctPop"
+	case ctWalk:
+		return "ctWalk"
+	case ctBound:
+		return "ctBound"
+	case ctRank:
+		return "ctRank"
+	case ctSelect:
+		return "ctSelect"
+	case ctRotate:
+		return "ctRotate"
+	case ctPivot:
+		return "ctPivot"
+	case ctFlip:
+		return "ctFlip"
+	case ctRebalance:
+		return "ctRebalance"
+	case ctSplit:
+		return "ctSplit"
+	case ctMerge:
+		return "ctMerge"
+	case ctDrain:
+		return "ctDrain"
+	case ctBatch:
+		return "ctBatch"
+	case ctClone:
+		return "ctClone"
+	case ctCopy:
+		return "ctCopy"
+	case ctDOT:
+		return "ctDOT"
+	case ctJSON:
+		return "ctJSON"
+	case ctReplace:
+		return "ctReplace"
 	default:
 		panic("unrecognized command type")
 	}
 }
 
+// Result is what gets sent back on the channel passed to Insert, Contains and
+// Delete. Ok is true if the value was inserted, found or deleted respectively.
+// Err is set if the value could not be compared against a value already in the
+// tree (for example NotComparable from a Comparer); in that case Ok is false
+// and the tree is left as it was.
+type Result struct {
+	Ok  bool
+	Err error
+
+	// payload is the value stored alongside the key when the tree is being
+	// used as a Map: the previous value for a put, the current value for a
+	// get and the removed value for a delete.
+	payload any
+
+	// count is how many times the value is in the tree, for Count
+	count int
+}
+
 type cmd interface {
 	typ() cmdType
 }
 
-type insertCmd struct {
-	reschan chan bool
-	val     Comparer
+type insertCmd[T any] struct {
+	reschan chan Result
+	val     T
+
+	// payload goes along with val into a new node. If replace is set it also
+	// overwrites the payload of an existing node holding val.
+	payload any
+	replace bool
+
+	ack chan subtree
+
+	// root is set by the manager on the command it sends to the root node
+	root bool
 }
 
-func (c insertCmd) typ() cmdType {
+func (c insertCmd[T]) typ() cmdType {
 	return ctInsert
 }
 
-type containsCmd struct {
-	reschan chan bool
-	val     Comparer
+type containsCmd[T any] struct {
+	reschan chan Result
+	val     T
+
+	// in a Splay tree finding a value changes the shape of the tree, so
+	// contains waits on the way back up like insert and delete do
+	ack  chan subtree
+	root bool
 }
 
-func (c containsCmd) typ() cmdType {
+func (c containsCmd[T]) typ() cmdType {
 	return ctContains
 }
 
-type deleteCmd struct {
-	reschan chan bool
-	val     Comparer
-	left    bool
+type deleteCmd[T any] struct {
+	reschan chan Result
+	val     T
+	ack     chan subtree
+	root    bool
 }
 
-func (c deleteCmd) typ() cmdType {
+func (c deleteCmd[T]) typ() cmdType {
 	return ctDelete
 }
 
@@ -99,28 +202,80 @@ func (c printCmd) typ() cmdType {
 	return ctPrint
 }
 
-type newChildCmd struct {
-	childchan chan cmd
-	left      bool
+// extractMinCmd pulls the smallest value out of a subtree, or the largest if max
+// is set. If one is set and the value is in a multiset more than once, only one
+// occurrence is taken and the node stays put.
+type extractMinCmd[T any] struct {
+	reschan chan subtreeMinResponse[T]
+	max     bool
+	one     bool
+	root    bool
 }
 
-func (c newChildCmd) typ() cmdType {
-	return ctNewChild
+func (c extractMinCmd[T]) typ() cmdType {
+	return ctExtractMin
 }
 
-type extractMinCmd struct {
-	reschan chan subtreeMinResponse
-	first   bool
+type subtreeMinResponse[T any] struct {
+	val     T
+	payload any
+	count   int
+	prio    int64
+
+	// what's left in the spot the extract came from
+	rest subtree
 }
 
-func (c extractMinCmd) typ() cmdType {
-	return ctExtractMin
+type closeCmd struct {
+	reschan chan struct{}
+}
+
+func (c closeCmd) typ() cmdType {
+	return ctClose
 }
 
-type subtreeMinResponse struct {
-	val       Comparer
-	newchild  bool
-	childchan chan cmd
+type lenCmd struct {
+	reschan chan int
+}
+
+func (c lenCmd) typ() cmdType {
+	return ctLen
+}
+
+// subtree is what a node knows about one of its children: the channel to reach
+// it and some facts about everything hanging off it. A nil ch means there's no
+// child there.
+//
+// Whenever a command that can change the shape of the tree is sent down, the
+// parent waits for the child to send back a fresh subtree describing whatever
+// sits in its spot once the command is done. That could be the same node, one
+// of its children that got promoted, or nothing at all. Since the parent is
+// waiting, a child never has to send an unsolicited message up the tree, which
+// could deadlock against the parent sending one down.
+type subtree struct {
+	ch   chan cmd
+	size int
+
+	// height is the number of nodes on the longest path down from the top of
+	// the subtree, and lean is the height of its left side minus the height of
+	// its right. Both are 0 for an empty subtree.
+	height int
+	lean   int
+
+	// red is the colour of the top node in a red-black tree, and leftRed the
+	// colour of its left child.
+	red     bool
+	leftRed bool
+
+	// prio is the priority of the top node in a Treap
+	prio int64
+
+	// splay says where the value the last write touched is, in a Splay tree.
+	// It only means anything in the ack it came back in.
+	splay splayPos
+
+	// keys is how many values the top node holds, in a BTree
+	keys int
 }
 
 ////////////////////////////
@@ -128,353 +283,558 @@ type subtreeMinResponse struct {
 ////////////////////////////
 
 // Goroutree is a tree set represented by a set of running goroutines, one per
-// node in the tree. It is essentially an actor-based tree that holds
-// machine-sized integers. The tree is unbalanced and does no rotations, so a
-// series of inserts and deletes can make it very unbalanced. The tree is not
-// going to be bombproof (because this is for a blog post) and probably has some
-// obvious races.
-type Goroutree struct {
-	cmdchan chan cmd
+// node in the tree. It is essentially an actor-based tree that holds values of
+// any type that can be put in order. By default the tree is unbalanced and does
+// no rotations, so a series of inserts and deletes can make it very unbalanced;
+// WithBalance turns on rotations that keep it shallow.
+//
+// Every write holds the path it takes until it gets to the bottom and hears
+// back from each node on the way, since that's how every node keeps its subtree
+// size (and height and colour) up to date for Len, Rank, Select and the
+// balancing modes. That includes the manager, so writes are done one after
+// another from start to finish, and a read that arrives while a write is under
+// way waits for it to be done before it goes down the tree. Reads that come one
+// after another with no writes between them still go down the tree together.
+//
+// The tree is not going to be bombproof (because this is for a blog post) and
+// probably has some obvious races.
+type Goroutree[T any] struct {
+	cmdchan   chan cmd
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// the same config the manager and nodes have. Nothing here may change
+	// it, only read it.
+	cfg *config[T]
+
+	// how many callers are waiting for the manager to take a command
+	queued atomic.Int64
+}
+
+// config holds everything the nodes of a single tree share. It is created once
+// per tree and handed to every node that gets spawned.
+type config[T any] struct {
+	compare func(a, b T) (int, error)
+	options
+
+	// where a Treap gets its node priorities from
+	rngMu sync.Mutex
+	rng   *rand.Rand
+
+	// the most values a BTree node holds
+	block int
 }
 
-// New creates a new empty Goroutree
-func New() *Goroutree {
-	cmdchan := make(chan cmd)
-	go manager(cmdchan)
+// New creates a new empty Goroutree of Comparer values, ordered by their
+// Compare method.
+func New(opts ...Option) *Goroutree[Comparer] {
+	return newTree(func(a, b Comparer) (int, error) {
+		return a.Compare(b)
+	}, opts)
+}
 
-	return &Goroutree{cmdchan}
+// NewOrdered creates a new empty Goroutree of values that can be ordered with
+// the < operator.
+func NewOrdered[T cmp.Ordered](opts ...Option) *Goroutree[T] {
+	return NewFunc(cmp.Compare[T], opts...)
 }
 
-func manager(main chan cmd) {
-	var cmdchan chan cmd
+// NewFunc creates a new empty Goroutree ordered by the given function, which
+// should return a negative number when a < b, zero when a == b and a positive
+// number when a > b.
+func NewFunc[T any](compare func(a, b T) int, opts ...Option) *Goroutree[T] {
+	return newTree(func(a, b T) (int, error) {
+		return compare(a, b), nil
+	}, opts)
+}
+
+func newTree[T any](compare func(a, b T) (int, error), opts []Option) *Goroutree[T] {
+	return start(newConfig(compare, opts), subtree{})
+}
+
+func newConfig[T any](compare func(a, b T) (int, error), opts []Option) *config[T] {
+	cfg := &config[T]{compare: compare}
+	for _, opt := range opts {
+		opt(&cfg.options)
+	}
+
+	if cfg.balance == Treap {
+		seed := cfg.seed
+		if !cfg.seeded {
+			seed = rand.Int63()
+		}
+		cfg.rng = rand.New(rand.NewSource(seed))
+	}
+
+	return cfg
+}
+
+// start runs a manager for a tree that already has root in it, which is empty
+// for a new tree.
+func start[T any](cfg *config[T], root subtree) *Goroutree[T] {
+	g := &Goroutree[T]{
+		cmdchan: make(chan cmd),
+		done:    make(chan struct{}),
+		cfg:     cfg,
+	}
+	go manager(g.cmdchan, cfg, root, &g.queued)
+
+	return g
+}
+
+// manager is the parent of the root node, so it needs somewhere to hear back
+// about the root being replaced. That also keeps the size of the whole tree
+// here so Len never has to touch a node. Every command sent on main has been
+// counted in queued, and stops being counted once the manager has it.
+func manager[T any](main chan cmd, cfg *config[T], root subtree, queued *atomic.Int64) {
+	ackchan := make(chan subtree)
+
+	insert := func(ic insertCmd[T]) {
+		if root.ch == nil {
+			// the root of a red-black tree is always black
+			rn := newNode(ic.val, ic.payload, cfg)
+			rn.red = false
+			root = rn.spawn()
+			ic.reschan <- Result{Ok: true}
+			return
+		}
+
+		ic.ack = ackchan
+		ic.root = true
+		root.ch <- ic
+		root = <-ackchan
+	}
+
+	contains := func(cc containsCmd[T]) {
+		if root.ch == nil {
+			cc.reschan <- Result{}
+			return
+		}
+
+		if cfg.balance != Splay {
+			root.ch <- cc
+			return
+		}
+
+		cc.ack = ackchan
+		cc.root = true
+		root.ch <- cc
+		root = <-ackchan
+	}
+
+	del := func(dc deleteCmd[T]) {
+		if root.ch == nil {
+			dc.reschan <- Result{}
+			return
+		}
+
+		// a Splay tree brings the value up to the root first, and the
+		// delete takes it out from there
+		if cfg.balance == Splay {
+			reschan := make(chan Result, 1)
+			root.ch <- containsCmd[T]{
+				reschan: reschan,
+				val:     dc.val,
+				ack:     ackchan,
+				root:    true,
+			}
+			root = <-ackchan
+
+			if res := <-reschan; !res.Ok {
+				dc.reschan <- Result{Err: res.Err}
+				return
+			}
+		}
+
+		dc.ack = ackchan
+		dc.root = true
+		root.ch <- dc
+		root = <-ackchan
+	}
 
 	for c := range main {
+		queued.Add(-1)
+
 		switch c.typ() {
 		case ctInsert:
-			if cmdchan == nil {
-				ic := c.(insertCmd)
-				cmdchan = spawn(ic.val, main)
-				ic.reschan <- true
-				continue
-			}
-
-			cmdchan <- c
+			insert(c.(insertCmd[T]))
 
 		case ctContains:
-			if cmdchan == nil {
-				cc := c.(containsCmd)
-				cc.reschan <- false
-				continue
-			}
-
-			cmdchan <- c
+			contains(c.(containsCmd[T]))
 
 		case ctDelete:
-			if cmdchan == nil {
-				dc := c.(deleteCmd)
-				dc.reschan <- false
-				continue
-			}
+			del(c.(deleteCmd[T]))
+
+		case ctBatch:
+			bc := c.(batchCmd[T])
+
+			switch {
+			case len(bc.vals) == 0:
+				bc.res.wg.Done()
+
+			case !bc.fansOut(cfg):
+				// the tree can only take these one value at a time, but
+				// they still don't each need a trip from the caller
+				for i, val := range bc.vals {
+					reschan := make(chan Result, 1)
+
+					switch bc.op {
+					case batchInsert:
+						insert(insertCmd[T]{reschan: reschan, val: val})
+					case batchContains:
+						contains(containsCmd[T]{reschan: reschan, val: val})
+					case batchDelete:
+						del(deleteCmd[T]{reschan: reschan, val: val})
+					}
+
+					res := <-reschan
+					bc.res.ok[bc.idx[i]] = res.Ok
+					bc.res.errs[bc.idx[i]] = res.Err
+				}
+				bc.res.wg.Done()
+
+			case root.ch == nil:
+				if bc.op == batchInsert {
+					bc.plant(&root, cfg, ackchan)
+				}
+				bc.res.wg.Done()
 
-			cmdchan <- c
+			case bc.op == batchContains:
+				root.ch <- bc
+
+			default:
+				bc.ack = ackchan
+				root.ch <- bc
+				root = <-ackchan
+			}
 
 		case ctPrint:
-			if cmdchan == nil {
+			if root.ch == nil {
 				pc := c.(printCmd)
 				pc.w.Write([]byte("\n"))
 				pc.reschan <- struct{}{}
+				continue
 			}
 
-			cmdchan <- c
+			root.ch <- c
 
-		case ctNewChild:
-			nc := c.(newChildCmd)
-			cmdchan = nc.childchan
+		case ctClose:
+			cc := c.(closeCmd)
 
-		default:
-			panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", c))
-		}
-	}
-}
+			if root.ch != nil {
+				reschan := make(chan struct{})
+				root.ch <- closeCmd{reschan: reschan}
+				<-reschan
+			}
 
-// Insert adds a new value into the set if it does not already exist. The channel
-// passed will receive a true if the value was successfully inserted and a false
-// if the value already existed.
-func (g *Goroutree) Insert(reschan chan bool, val Comparer) {
-	g.cmdchan <- insertCmd{
-		reschan: reschan,
-		val:     val,
-	}
-}
+			cc.reschan <- struct{}{}
+			return
 
-// Contains will tell if the set contains the given value. The channel passed will
-// receive a true if the value does exist in the set and a false if not.
-func (g *Goroutree) Contains(reschan chan bool, val Comparer) {
-	g.cmdchan <- containsCmd{
-		reschan: reschan,
-		val:     val,
-	}
-}
+		case ctLen:
+			lc := c.(lenCmd)
+			lc.reschan <- root.size
 
-// Delete removes a value from the tree set if it exists. The channel passed will
-// receive a true if the value did exist in the set and a false if not.
-func (g *Goroutree) Delete(reschan chan bool, val Comparer) {
-	g.cmdchan <- deleteCmd{
-		reschan: reschan,
-		val:     val,
-	}
-}
+		case ctPeek:
+			if root.ch == nil {
+				pc := c.(peekCmd[T])
+				pc.reschan <- valueResult[T]{}
+				continue
+			}
 
-// Print will print out the tree. This is a blocking operation, so no other
-// messages can be processed while printing. This is for debugging purposes only.
-func (g *Goroutree) Print(reschan chan struct{}, w io.Writer) {
-	g.cmdchan <- printCmd{
-		reschan: reschan,
-		w:       w,
-	}
-}
+			root.ch <- c
 
-// spawn creates a new node that owns a value.
-// within this function is the logic that each node runs. Essentially it is an
-// infinite loop that responds to messages sent on its command channel. It then
-// decides to either act on that message or pass it on down the tree.
-func spawn(val Comparer, parentchan chan cmd) chan cmd {
+		case ctPop:
+			// the manager is the root's parent, so it takes care of the
+			// root being the value that's popped.
+			pc := c.(popCmd[T])
+			if root.ch == nil {
+				pc.reschan <- valueResult[T]{}
+				continue
+			}
 
-	cmdchan := make(chan cmd)
+			reschan := make(chan subtreeMinResponse[T])
+			root.ch <- extractMinCmd[T]{
+				reschan: reschan,
+				max:     pc.max,
+				one:     cfg.multiset,
+				root:    true,
+			}
 
-	// cmdchan will be a stream of commands to be done in this node
-	// val is a constant value that this node holds
-	go func(cmdchan, parentchan chan cmd, val Comparer) {
-		var left, right chan cmd
+			res := <-reschan
+			root = res.rest
 
-		for cm := range cmdchan {
-			switch cm.typ() {
-			case ctInsert:
-				c := cm.(insertCmd)
+			pc.reschan <- valueResult[T]{val: res.val, ok: true}
 
-				comparison, _ := c.val.Compare(val)
+		case ctWalk:
+			if root.ch == nil {
+				wc := c.(walkCmd[T])
+				wc.reschan <- nil
+				continue
+			}
 
-				if comparison == 0 {
-					c.reschan <- false
-					continue
-				}
+			root.ch <- c
 
-				// left branch
-				if comparison == -1 {
-					// if the left node exists, send it down.
-					if left != nil {
-						left <- c
-						continue
-					}
+		case ctBound:
+			if root.ch == nil {
+				bc := c.(boundCmd[T])
+				bc.reschan <- valueResult[T]{}
+				continue
+			}
 
-					left = spawn(c.val, cmdchan)
-					c.reschan <- true
-					continue
-				}
+			root.ch <- c
 
-				// right branch
-				if right != nil {
-					right <- c
-					continue
-				}
+		case ctRank:
+			if root.ch == nil {
+				rc := c.(rankCmd[T])
+				rc.reschan <- Result{}
+				continue
+			}
 
-				right = spawn(c.val, cmdchan)
-				c.reschan <- true
+			root.ch <- c
 
-			case ctContains:
-				c := cm.(containsCmd)
-				comparison, _ := c.val.Compare(val)
+		case ctSelect:
+			// the manager knows how big the tree is, so anything out of range
+			// never has to go down it.
+			sc := c.(selectCmd[T])
+			if sc.k < 0 || sc.k >= root.size {
+				sc.reschan <- valueResult[T]{}
+				continue
+			}
 
-				if c.val == val {
-					c.reschan <- true
-					continue
-				}
+			root.ch <- c
 
-				// Go right if the value is bigger,
-				// left if smaller
-				if comparison == 1 && right != nil {
-					right <- c
-					continue
-				}
+		case ctRebalance:
+			// nothing else gets to the old nodes while this runs, and
+			// anything sent in the meantime waits for the new root
+			rc := c.(rebalanceCmd)
+			if root.ch != nil {
+				old := root
+				root = build(snapshot[T](old), cfg)
 
-				if left != nil {
-					left <- c
-					continue
-				}
+				reschan := make(chan struct{})
+				old.ch <- closeCmd{reschan: reschan}
+				<-reschan
+			}
 
-				// if we get here, the value does not exist in the tree
-				c.reschan <- false
+			rc.reschan <- struct{}{}
 
-			case ctDelete:
-				c := cm.(deleteCmd)
+		case ctClone:
+			cc := c.(cloneCmd[T])
+			ccfg := cfg.clone()
 
-				// if a match, delete this node.
-				if c.val == val {
+			var copied subtree
+			if root.ch != nil {
+				reschan := make(chan subtree)
+				root.ch <- copyCmd[T]{reschan: reschan, cfg: ccfg}
+				copied = <-reschan
+			}
 
-					// if this is a leaf node with no children, it just returns
-					if left == nil && right == nil {
-						// send death message to parent
-						parentchan <- newChildCmd{
-							left:      c.left,
-							childchan: nil,
-						}
+			cc.reschan <- start(ccfg, copied)
 
-						c.reschan <- true
-						return
-					}
+		case ctDOT:
+			c.(dotCmd).manager(root, queued.Load())
 
-					// one child, promote it to current position by sending parent a message
-					// we know at this point that one is not nil, so this checks if we have
-					// one and only one not nil child.
-					if left == nil || right == nil {
-
-						var childchan chan cmd
-						if left != nil {
-							childchan = left
-						} else {
-							childchan = right
-						}
-
-						// promote child
-						parentchan <- newChildCmd{
-							left:      c.left,
-							childchan: childchan,
-						}
-
-						c.reschan <- true
-						return
-					}
+		case ctJSON:
+			if root.ch == nil {
+				jc := c.(jsonCmd[T])
+				jc.reschan <- jsonResult{}
+				continue
+			}
 
-					// At this point, we need to substitute the current node with either the
-					// maximum node on the left subtree or the minimum node on the right subtree.
-					// For simplicity, this implementation always chooses to pull the minimum node
-					// out of the right subtree.
-					// The pattern is to send a message down the right subtree to find the minimum
-					// node. Once it's found, it will have either one child or none. In this special
-					// case, the node that is found will take care of removing itself and send its
-					// value back to this node. TO make things simpler, this node will simply take
-					// the value and assign it as its owned value. I could do some trickery with
-					// reassigning channels all over the place to physically transplant that other
-					// node to this position, but that just seems silly to do if I can get away with
-					// just taking ownership of that value.
-
-					reschan := make(chan subtreeMinResponse)
-					right <- extractMinCmd{
-						reschan: reschan,
-						first:   true,
-					}
+			root.ch <- c
 
-					res := <-reschan
-					val = res.val
+		case ctReplace:
+			// the same as the end of a rebalance
+			rc := c.(replaceCmd)
+			if root.ch != nil {
+				reschan := make(chan struct{})
+				root.ch <- closeCmd{reschan: reschan}
+				<-reschan
+			}
 
-					if res.newchild {
-						right = res.childchan
-					}
+			root = rc.root
+			rc.reschan <- struct{}{}
 
-					c.reschan <- true
-					continue
-				}
+		default:
+			panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", c))
+		}
+	}
+}
 
-				comparison, _ := c.val.Compare(val)
+// send hands a command to the manager, or returns ErrClosed if the tree has
+// been closed.
+func (g *Goroutree[T]) send(c cmd) error {
+	return g.handoff(context.Background(), c)
+}
 
-				if comparison == 1 && right != nil {
-					c.left = false
-					right <- c
-					continue
-				}
+// handoff hands a command to the manager, giving up if the tree is closed or
+// ctx is done first. The command is counted in queued until the manager takes
+// it, and the manager is the one that takes it back off the count, so a
+// command is never counted after it's left the queue.
+func (g *Goroutree[T]) handoff(ctx context.Context, c cmd) error {
+	g.queued.Add(1)
+
+	select {
+	case g.cmdchan <- c:
+		return nil
+	case <-g.done:
+		g.queued.Add(-1)
+		return ErrClosed
+	case <-ctx.Done():
+		g.queued.Add(-1)
+		return ctx.Err()
+	}
+}
 
-				if left != nil {
-					c.left = true
-					left <- c
-					continue
-				}
+// Insert adds a new value into the set if it does not already exist. The channel
+// passed will receive a Result with Ok set if the value was successfully
+// inserted and unset if the value already existed. In a Multiset tree the value
+// is always inserted and its count goes up by one. If the tree is closed,
+// ErrClosed is returned and nothing is sent on the channel.
+func (g *Goroutree[T]) Insert(reschan chan Result, val T) error {
+	return g.send(insertCmd[T]{
+		reschan: reschan,
+		val:     val,
+	})
+}
 
-				// if we get here, the value does not exist in the tree
-				c.reschan <- false
+// Contains will tell if the set contains the given value. The channel passed will
+// receive a Result with Ok set if the value does exist in the set and unset if
+// not. If the tree is closed, ErrClosed is returned and nothing is sent on the
+// channel.
+func (g *Goroutree[T]) Contains(reschan chan Result, val T) error {
+	return g.send(containsCmd[T]{
+		reschan: reschan,
+		val:     val,
+	})
+}
 
-			case ctExtractMin:
-				c := cm.(extractMinCmd)
+// Delete removes a value from the tree set if it exists. The channel passed will
+// receive a Result with Ok set if the value did exist in the set and unset if
+// not. In a Multiset tree only one occurrence is removed. If the tree is closed,
+// ErrClosed is returned and nothing is sent on the channel.
+func (g *Goroutree[T]) Delete(reschan chan Result, val T) error {
+	return g.send(deleteCmd[T]{
+		reschan: reschan,
+		val:     val,
+	})
+}
 
-				// The first node might be the one that we want, in which case
-				// we need to know. That node will be the right child and not
-				// the left like all others
-				if left != nil {
-					if c.first {
-						c.first = false
-					}
-					left <- c
-					continue
-				}
+// InsertCtx is the same as Insert, but blocks until the value has been inserted
+// and returns the result directly. If ctx is done first, ctx.Err() is returned.
+func (g *Goroutree[T]) InsertCtx(ctx context.Context, val T) (bool, error) {
+	reschan := make(chan Result, 1)
+	res := g.wait(ctx, insertCmd[T]{
+		reschan: reschan,
+		val:     val,
+	}, reschan)
 
-				// this is the right child of the node that is being deleted
-				// it needs special attention here because there can be a race
-				// between the parentchan message below and an external command.
-				// The same place we're trying to send the parentchan message is
-				// waiting on the reschan, so this special message takes care of
-				// both at once.
-				if c.first {
-					c.reschan <- subtreeMinResponse{
-						val:       val,
-						newchild:  true,
-						childchan: right,
-					}
-				}
+	return res.Ok, res.Err
+}
 
-				// then replace self at parent with whatever is at the right
-				// nil is fine here, so no check
-				parentchan <- newChildCmd{
-					left:      !c.first,
-					childchan: right,
-				}
+// ContainsCtx is the same as Contains, but blocks until the tree has answered
+// and returns the result directly. If ctx is done first, ctx.Err() is returned.
+func (g *Goroutree[T]) ContainsCtx(ctx context.Context, val T) (bool, error) {
+	reschan := make(chan Result, 1)
+	res := g.wait(ctx, containsCmd[T]{
+		reschan: reschan,
+		val:     val,
+	}, reschan)
 
-				// send back the min value for this subtree
-				c.reschan <- subtreeMinResponse{val: val}
+	return res.Ok, res.Err
+}
 
-				return
+// DeleteCtx is the same as Delete, but blocks until the value has been deleted
+// and returns the result directly. If ctx is done first, ctx.Err() is returned.
+func (g *Goroutree[T]) DeleteCtx(ctx context.Context, val T) (bool, error) {
+	reschan := make(chan Result, 1)
+	res := g.wait(ctx, deleteCmd[T]{
+		reschan: reschan,
+		val:     val,
+	}, reschan)
 
-			case ctNewChild:
-				c := cm.(newChildCmd)
+	return res.Ok, res.Err
+}
 
-				if c.left {
-					left = c.childchan
-					continue
-				}
+// Count returns how many times val is in the tree. Unless the tree was created
+// with the Multiset option, that's always 0 or 1.
+func (g *Goroutree[T]) Count(val T) (int, error) {
+	reschan := make(chan Result, 1)
+	res := g.wait(context.Background(), containsCmd[T]{
+		reschan: reschan,
+		val:     val,
+	}, reschan)
+
+	return res.count, res.Err
+}
 
-				right = c.childchan
+// Len returns the number of values in the tree. In a Multiset tree every
+// occurrence of a value is counted. The answer reflects every Insert and Delete
+// that was sent before it, since the manager doesn't take any more commands
+// until the tree has finished with each of those.
+func (g *Goroutree[T]) Len() (int, error) {
+	reschan := make(chan int, 1)
+	return request(context.Background(), g, lenCmd{reschan: reschan}, reschan)
+}
 
-			case ctPrint:
-				// Inorder printing traversal of the tree
-				c := cm.(printCmd)
+// wait sends a command that answers with a Result and waits for it. Any error
+// from sending or waiting ends up in the Result.
+func (g *Goroutree[T]) wait(ctx context.Context, c cmd, reschan chan Result) Result {
+	res, err := request(ctx, g, c, reschan)
+	if err != nil {
+		return Result{Err: err}
+	}
 
-				// make a new command for the children. FOR THE CHILDREN.
-				// Each child gets the command and a chance to finish its work before
-				// this node mvoes on. This means that printing the tree is basically
-				// a blocking operation in which no other operations can be done.
-				childcmd := c
-				childcmd.reschan = make(chan struct{})
-				childcmd.level++
+	return res
+}
 
-				if left != nil {
-					left <- childcmd
-					<-childcmd.reschan
-				}
+// request sends a command and waits for its result, giving up if ctx is done
+// first. reschan needs to have room for the one reply, so that a node
+// answering after the caller has gone away can drop the reply in the buffer
+// and carry on instead of blocking forever on a channel no one will read.
+// Giving up does not take the command back; it may still take effect.
+func request[T, R any](ctx context.Context, g *Goroutree[T], c cmd, reschan chan R) (R, error) {
+	var zero R
 
-				// no this is not very efficient, but this is for debugging
-				indent := bytes.Repeat([]byte(" "), c.level)
-				fmt.Fprintf(c.w, "%s%d\n", indent, val)
+	if err := g.handoff(ctx, c); err != nil {
+		return zero, err
+	}
 
-				if right != nil {
-					right <- childcmd
-					<-childcmd.reschan
-				}
+	select {
+	case res := <-reschan:
+		return res, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// Print will print out the tree. This is a blocking operation, so no other
+// messages can be processed while printing. This is for debugging purposes only.
+// Each value is on its own line, indented by its depth; in a RedBlack tree the
+// red nodes are marked.
+func (g *Goroutree[T]) Print(reschan chan struct{}, w io.Writer) error {
+	return g.send(printCmd{
+		reschan: reschan,
+		w:       w,
+	})
+}
 
-				c.reschan <- struct{}{}
+// Close shuts down the manager and every node in the tree, and waits for all of
+// their goroutines to exit. Any operation after Close returns ErrClosed,
+// including a second call to Close. Results from earlier operations must have
+// been read off their channels first, since a node cannot shut down while it is
+// still trying to send one.
+func (g *Goroutree[T]) Close() error {
+	err := ErrClosed
 
-			default:
-				panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", cm))
-			}
-		}
-	}(cmdchan, parentchan, val)
+	g.closeOnce.Do(func() {
+		reschan := make(chan struct{})
+		g.handoff(context.Background(), closeCmd{reschan: reschan})
+		<-reschan
+
+		close(g.done)
+		err = nil
+	})
 
-	return cmdchan
+	return err
 }
@@ -16,8 +16,10 @@ package goroutree_test
 
 import (
 	"bytes"
+	"runtime"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/ScottMansfield/goroutree"
 )
@@ -26,12 +28,12 @@ func TestInsert(t *testing.T) {
 	t.Run("One", func(t *testing.T) {
 		g := goroutree.New()
 
-		boolreschan := make(chan bool)
+		boolreschan := make(chan goroutree.Result)
 
 		g.Insert(boolreschan, goroutree.Int(5))
 
 		b := <-boolreschan
-		if b != true {
+		if !b.Ok {
 			t.Fatalf("Expected a true result from inserting")
 		}
 
@@ -52,19 +54,19 @@ func TestInsert(t *testing.T) {
 		t.Run("SecondLess", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -84,19 +86,19 @@ func TestInsert(t *testing.T) {
 		t.Run("SecondGreater", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -116,19 +118,19 @@ func TestInsert(t *testing.T) {
 		t.Run("SecondEqual", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatalf("Expected a false result from inserting duplicate")
 			}
 
@@ -150,26 +152,26 @@ func TestInsert(t *testing.T) {
 		t.Run("Balanced", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -189,26 +191,26 @@ func TestInsert(t *testing.T) {
 		t.Run("SkewLeft", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -228,26 +230,26 @@ func TestInsert(t *testing.T) {
 		t.Run("SkewRight", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -267,26 +269,26 @@ func TestInsert(t *testing.T) {
 		t.Run("SkewRightLeft", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -306,26 +308,26 @@ func TestInsert(t *testing.T) {
 		t.Run("SkewLeftRight", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
@@ -345,26 +347,26 @@ func TestInsert(t *testing.T) {
 		t.Run("ThirdEqualRoot", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatalf("Expected a false result from inserting")
 			}
 
@@ -384,26 +386,26 @@ func TestInsert(t *testing.T) {
 		t.Run("ThirdEqualLeft", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatalf("Expected a false result from inserting")
 			}
 
@@ -423,26 +425,26 @@ func TestInsert(t *testing.T) {
 		t.Run("ThirdEqualRight", func(t *testing.T) {
 			g := goroutree.New()
 
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatalf("Expected a false result from inserting")
 			}
 
@@ -472,7 +474,7 @@ func TestInsert(t *testing.T) {
 		//7,3,1,0,2,5,4,6,11,9,8,10,13,12,14
 		g := goroutree.New()
 
-		boolreschan := make(chan bool, 15)
+		boolreschan := make(chan goroutree.Result, 15)
 
 		// create a balanced tree of 15 items
 		g.Insert(boolreschan, goroutree.Int(7))
@@ -493,7 +495,7 @@ func TestInsert(t *testing.T) {
 
 		for i := 0; i < 15; i++ {
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 		}
@@ -516,48 +518,48 @@ func TestInsert(t *testing.T) {
 func TestContains(t *testing.T) {
 	t.Run("Empty", func(t *testing.T) {
 		g := goroutree.New()
-		boolreschan := make(chan bool)
+		boolreschan := make(chan goroutree.Result)
 		g.Contains(boolreschan, goroutree.Int(4))
 
 		b := <-boolreschan
-		if b != false {
+		if b.Ok {
 			t.Fatal("Expected false result from contains")
 		}
 	})
 	t.Run("One", func(t *testing.T) {
 		t.Run("Hit", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Contains(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from contains")
 			}
 		})
 		t.Run("Miss", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Contains(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatal("Expected false result from contains")
 			}
 		})
@@ -566,77 +568,77 @@ func TestContains(t *testing.T) {
 		t.Run("Hit", func(t *testing.T) {
 			t.Run("Root", func(t *testing.T) {
 				g := goroutree.New()
-				boolreschan := make(chan bool)
+				boolreschan := make(chan goroutree.Result)
 
 				g.Insert(boolreschan, goroutree.Int(4))
 
 				b := <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Insert(boolreschan, goroutree.Int(6))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Contains(boolreschan, goroutree.Int(4))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatal("Expected true result from contains")
 				}
 			})
 			t.Run("Child", func(t *testing.T) {
 				t.Run("Left", func(t *testing.T) {
 					g := goroutree.New()
-					boolreschan := make(chan bool)
+					boolreschan := make(chan goroutree.Result)
 
 					g.Insert(boolreschan, goroutree.Int(4))
 
 					b := <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatalf("Expected a true result from inserting")
 					}
 
 					g.Insert(boolreschan, goroutree.Int(2))
 
 					b = <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatalf("Expected a true result from inserting")
 					}
 
 					g.Contains(boolreschan, goroutree.Int(2))
 
 					b = <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatal("Expected true result from contains")
 					}
 				})
 				t.Run("Right", func(t *testing.T) {
 					g := goroutree.New()
-					boolreschan := make(chan bool)
+					boolreschan := make(chan goroutree.Result)
 
 					g.Insert(boolreschan, goroutree.Int(4))
 
 					b := <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatalf("Expected a true result from inserting")
 					}
 
 					g.Insert(boolreschan, goroutree.Int(6))
 
 					b = <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatalf("Expected a true result from inserting")
 					}
 
 					g.Contains(boolreschan, goroutree.Int(6))
 
 					b = <-boolreschan
-					if b != true {
+					if !b.Ok {
 						t.Fatal("Expected true result from contains")
 					}
 				})
@@ -645,37 +647,37 @@ func TestContains(t *testing.T) {
 		t.Run("Miss", func(t *testing.T) {
 			t.Run("Left", func(t *testing.T) {
 				g := goroutree.New()
-				boolreschan := make(chan bool)
+				boolreschan := make(chan goroutree.Result)
 
 				g.Insert(boolreschan, goroutree.Int(4))
 
 				b := <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Contains(boolreschan, goroutree.Int(3))
 
 				b = <-boolreschan
-				if b != false {
+				if b.Ok {
 					t.Fatal("Expected true result from contains")
 				}
 			})
 			t.Run("Right", func(t *testing.T) {
 				g := goroutree.New()
-				boolreschan := make(chan bool)
+				boolreschan := make(chan goroutree.Result)
 
 				g.Insert(boolreschan, goroutree.Int(4))
 
 				b := <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Contains(boolreschan, goroutree.Int(5))
 
 				b = <-boolreschan
-				if b != false {
+				if b.Ok {
 					t.Fatal("Expected true result from contains")
 				}
 			})
@@ -686,30 +688,30 @@ func TestContains(t *testing.T) {
 func TestDelete(t *testing.T) {
 	t.Run("Empty", func(t *testing.T) {
 		g := goroutree.New()
-		boolreschan := make(chan bool)
+		boolreschan := make(chan goroutree.Result)
 		g.Delete(boolreschan, goroutree.Int(4))
 
 		b := <-boolreschan
-		if b != false {
+		if b.Ok {
 			t.Fatal("Expected false result from delete")
 		}
 	})
 	t.Run("OneNode", func(t *testing.T) {
 		t.Run("Hit", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -728,19 +730,19 @@ func TestDelete(t *testing.T) {
 		})
 		t.Run("Miss", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != false {
+			if b.Ok {
 				t.Fatal("Expected false result from delete")
 			}
 
@@ -761,26 +763,26 @@ func TestDelete(t *testing.T) {
 	t.Run("OneChild", func(t *testing.T) {
 		t.Run("LeftChild", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(3))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(3))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -799,26 +801,26 @@ func TestDelete(t *testing.T) {
 		})
 		t.Run("RightChild", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -839,26 +841,26 @@ func TestDelete(t *testing.T) {
 			t.Run("PromoteLeft", func(t *testing.T) {
 
 				g := goroutree.New()
-				boolreschan := make(chan bool)
+				boolreschan := make(chan goroutree.Result)
 
 				g.Insert(boolreschan, goroutree.Int(4))
 
 				b := <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Insert(boolreschan, goroutree.Int(3))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Delete(boolreschan, goroutree.Int(4))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatal("Expected true result from delete")
 				}
 
@@ -878,26 +880,26 @@ func TestDelete(t *testing.T) {
 			t.Run("PromoteRight", func(t *testing.T) {
 
 				g := goroutree.New()
-				boolreschan := make(chan bool)
+				boolreschan := make(chan goroutree.Result)
 
 				g.Insert(boolreschan, goroutree.Int(4))
 
 				b := <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Insert(boolreschan, goroutree.Int(5))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatalf("Expected a true result from inserting")
 				}
 
 				g.Delete(boolreschan, goroutree.Int(4))
 
 				b = <-boolreschan
-				if b != true {
+				if !b.Ok {
 					t.Fatal("Expected true result from delete")
 				}
 
@@ -919,33 +921,33 @@ func TestDelete(t *testing.T) {
 	t.Run("TwoChildren", func(t *testing.T) {
 		t.Run("MinIsRightChild", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(3))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -964,40 +966,40 @@ func TestDelete(t *testing.T) {
 		})
 		t.Run("MinIsRightChildsLeftChild", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(3))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -1016,47 +1018,47 @@ func TestDelete(t *testing.T) {
 		})
 		t.Run("MinIsRightChildsLeftChildWithRightChild", func(t *testing.T) {
 			g := goroutree.New()
-			boolreschan := make(chan bool)
+			boolreschan := make(chan goroutree.Result)
 
 			g.Insert(boolreschan, goroutree.Int(4))
 
 			b := <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(3))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(7))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(5))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Insert(boolreschan, goroutree.Int(6))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatalf("Expected a true result from inserting")
 			}
 
 			g.Delete(boolreschan, goroutree.Int(4))
 
 			b = <-boolreschan
-			if b != true {
+			if !b.Ok {
 				t.Fatal("Expected true result from delete")
 			}
 
@@ -1076,6 +1078,249 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestOrdered(t *testing.T) {
+	t.Run("Int", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		boolreschan := make(chan goroutree.Result)
+
+		for _, i := range []int{5, 3, 8, 4} {
+			g.Insert(boolreschan, i)
+
+			b := <-boolreschan
+			if !b.Ok {
+				t.Fatalf("Expected a true result from inserting")
+			}
+		}
+
+		g.Contains(boolreschan, 4)
+
+		b := <-boolreschan
+		if !b.Ok {
+			t.Fatal("Expected true result from contains")
+		}
+
+		g.Delete(boolreschan, 5)
+
+		b = <-boolreschan
+		if !b.Ok {
+			t.Fatal("Expected true result from delete")
+		}
+
+		structreschan := make(chan struct{})
+		buf := &bytes.Buffer{}
+
+		g.Print(structreschan, buf)
+		<-structreschan
+
+		t.Logf("Printed tree: \n%s", buf.String())
+
+		gold := " 3\n  4\n8\n"
+		if buf.String() != gold {
+			t.Fatalf("Expected printed tree to be \"%s\" but got %s", gold, buf.String())
+		}
+	})
+	t.Run("String", func(t *testing.T) {
+		g := goroutree.NewOrdered[string]()
+		boolreschan := make(chan goroutree.Result)
+
+		for _, s := range []string{"m", "c", "x"} {
+			g.Insert(boolreschan, s)
+			<-boolreschan
+		}
+
+		g.Contains(boolreschan, "c")
+
+		b := <-boolreschan
+		if !b.Ok {
+			t.Fatal("Expected true result from contains")
+		}
+
+		g.Contains(boolreschan, "d")
+
+		b = <-boolreschan
+		if b.Ok {
+			t.Fatal("Expected false result from contains")
+		}
+
+		structreschan := make(chan struct{})
+		buf := &bytes.Buffer{}
+
+		g.Print(structreschan, buf)
+		<-structreschan
+
+		gold := " c\nm\n x\n"
+		if buf.String() != gold {
+			t.Fatalf("Expected printed tree to be \"%s\" but got %s", gold, buf.String())
+		}
+	})
+}
+
+func TestFunc(t *testing.T) {
+	// reverse order, so bigger values go to the left
+	g := goroutree.NewFunc(func(a, b int) int {
+		return b - a
+	})
+	boolreschan := make(chan goroutree.Result)
+
+	for _, i := range []int{5, 4, 6} {
+		g.Insert(boolreschan, i)
+
+		b := <-boolreschan
+		if !b.Ok {
+			t.Fatalf("Expected a true result from inserting")
+		}
+	}
+
+	structreschan := make(chan struct{})
+	buf := &bytes.Buffer{}
+
+	g.Print(structreschan, buf)
+	<-structreschan
+
+	t.Logf("Printed tree: \n%s", buf.String())
+
+	gold := " 6\n5\n 4\n"
+	if buf.String() != gold {
+		t.Fatalf("Expected printed tree to be \"%s\" but got %s", gold, buf.String())
+	}
+}
+
+// Str is a Comparer that can only be compared to other Strs
+type Str string
+
+func (s Str) Compare(value interface{}) (int, error) {
+	other, ok := value.(Str)
+	if !ok {
+		return 0, goroutree.NotComparable
+	}
+	if s < other {
+		return -1, nil
+	} else if s == other {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+func TestCompareError(t *testing.T) {
+	g := goroutree.New()
+	boolreschan := make(chan goroutree.Result)
+
+	g.Insert(boolreschan, Str("m"))
+	<-boolreschan
+	g.Insert(boolreschan, Str("c"))
+	<-boolreschan
+
+	t.Run("Insert", func(t *testing.T) {
+		g.Insert(boolreschan, goroutree.Int(4))
+
+		b := <-boolreschan
+		if b.Ok || b.Err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from inserting, got %+v", b)
+		}
+	})
+	t.Run("Contains", func(t *testing.T) {
+		g.Contains(boolreschan, goroutree.Int(4))
+
+		b := <-boolreschan
+		if b.Ok || b.Err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from contains, got %+v", b)
+		}
+	})
+	t.Run("Delete", func(t *testing.T) {
+		g.Delete(boolreschan, goroutree.Int(4))
+
+		b := <-boolreschan
+		if b.Ok || b.Err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from delete, got %+v", b)
+		}
+	})
+	t.Run("TreeUnchanged", func(t *testing.T) {
+		g.Delete(boolreschan, Str("c"))
+
+		b := <-boolreschan
+		if !b.Ok || b.Err != nil {
+			t.Fatalf("Expected a true result from delete, got %+v", b)
+		}
+
+		structreschan := make(chan struct{})
+		buf := &bytes.Buffer{}
+
+		g.Print(structreschan, buf)
+		<-structreschan
+
+		gold := "m\n"
+		if buf.String() != gold {
+			t.Fatalf("Expected printed tree to be \"%s\" but got %s", gold, buf.String())
+		}
+	})
+}
+
+func TestClose(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.New()
+
+		if err := g.Close(); err != nil {
+			t.Fatalf("Expected no error from closing, got %v", err)
+		}
+	})
+	t.Run("Twice", func(t *testing.T) {
+		g := goroutree.New()
+		g.Close()
+
+		if err := g.Close(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from closing twice, got %v", err)
+		}
+	})
+	t.Run("OperationsAfterClose", func(t *testing.T) {
+		g := genTreeLevels(5)
+		g.Close()
+
+		boolreschan := make(chan goroutree.Result)
+
+		if err := g.Insert(boolreschan, goroutree.Int(10)); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from insert, got %v", err)
+		}
+		if err := g.Contains(boolreschan, goroutree.Int(1)); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from contains, got %v", err)
+		}
+		if err := g.Delete(boolreschan, goroutree.Int(1)); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from delete, got %v", err)
+		}
+		if err := g.Print(make(chan struct{}), &bytes.Buffer{}); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from print, got %v", err)
+		}
+	})
+	t.Run("NoLeaks", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		g := goroutree.New()
+		boolreschan := make(chan goroutree.Result)
+
+		for _, i := range []int{7, 3, 11, 1, 5, 9, 13} {
+			g.Insert(boolreschan, goroutree.Int(i))
+			<-boolreschan
+		}
+
+		g.Delete(boolreschan, goroutree.Int(3))
+		<-boolreschan
+
+		if err := g.Close(); err != nil {
+			t.Fatalf("Expected no error from closing, got %v", err)
+		}
+
+		// the node goroutines have all replied by the time Close returns, but
+		// may not have been torn down by the runtime yet.
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+
+		if after := runtime.NumGoroutine(); after > before {
+			t.Fatalf("Expected %d goroutines after closing but there were %d", before, after)
+		}
+	})
+}
+
 // for 3, this should generate the tree
 //
 //    0
@@ -1084,9 +1329,9 @@ func TestDelete(t *testing.T) {
 //       \
 //        2
 //
-func genTreeLevels(levels int) *goroutree.Goroutree {
+func genTreeLevels(levels int) *goroutree.Goroutree[goroutree.Comparer] {
 	g := goroutree.New()
-	boolreschan := make(chan bool)
+	boolreschan := make(chan goroutree.Result)
 
 	for i := 0; i < levels; i++ {
 		g.Insert(boolreschan, goroutree.Int(i))
@@ -1097,7 +1342,7 @@ func genTreeLevels(levels int) *goroutree.Goroutree {
 }
 
 func benchInsert(b *testing.B, levels int) {
-	boolreschan := make(chan bool)
+	boolreschan := make(chan goroutree.Result)
 	g := genTreeLevels(levels)
 
 	for i := 0; i < b.N; i++ {
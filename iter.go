@@ -0,0 +1,77 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"context"
+	"iter"
+)
+
+// The iterators below all walk the tree the same way Print does, so what they
+// see is a consistent snapshot: each node holds on to the walk until
+// everything under it has been yielded, and the tree can't change (or answer
+// anything else) until the loop is over. That also means the body of the loop
+// must not use the same tree, or it will wait forever.
+//
+// Breaking out of the loop early is fine. The iterator calls off the walk and
+// waits for every node to let go of it before returning, so the tree is back
+// to normal by the time the code after the loop runs. Iterating over a closed
+// tree yields nothing.
+
+// All returns an iterator over the values in the tree in increasing order. In
+// a Multiset tree each value is yielded as many times as it was inserted.
+func (g *Goroutree[T]) All() iter.Seq[T] {
+	return g.values(false)
+}
+
+// Backward returns an iterator over the values in the tree in decreasing
+// order. In a Multiset tree each value is yielded as many times as it was
+// inserted.
+func (g *Goroutree[T]) Backward() iter.Seq[T] {
+	return g.values(true)
+}
+
+// Counts returns an iterator over the distinct values in the tree in
+// increasing order, along with how many times each one is in the tree. That's
+// always 1 unless the tree is a Multiset.
+func (g *Goroutree[T]) Counts() iter.Seq2[T, int] {
+	return func(yield func(T, int) bool) {
+		w, err := g.startWalk(context.Background(), nil, nil, false)
+		if err != nil {
+			return
+		}
+
+		w.each(yield)
+	}
+}
+
+func (g *Goroutree[T]) values(reverse bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		w, err := g.startWalk(context.Background(), nil, nil, reverse)
+		if err != nil {
+			return
+		}
+
+		w.each(func(val T, count int) bool {
+			for i := 0; i < count; i++ {
+				if !yield(val) {
+					return false
+				}
+			}
+
+			return true
+		})
+	}
+}
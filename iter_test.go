@@ -0,0 +1,110 @@
+package goroutree_test
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestIter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for v := range g.All() {
+			t.Fatalf("Expected nothing from an empty tree, got %d", v)
+		}
+	})
+	t.Run("All", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		gold := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}
+		if vals := slices.Collect(g.All()); !reflect.DeepEqual(vals, gold) {
+			t.Fatalf("Expected %v from all, got %v", gold, vals)
+		}
+	})
+	t.Run("Backward", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{5, 3, 8, 4} {
+			g.InsertCtx(ctx, i)
+		}
+
+		gold := []int{8, 5, 4, 3}
+		if vals := slices.Collect(g.Backward()); !reflect.DeepEqual(vals, gold) {
+			t.Fatalf("Expected %v from backward, got %v", gold, vals)
+		}
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{2, 1, 2, 3, 2} {
+			g.InsertCtx(ctx, i)
+		}
+
+		gold := []int{1, 2, 2, 2, 3}
+		if vals := slices.Collect(g.All()); !reflect.DeepEqual(vals, gold) {
+			t.Fatalf("Expected %v from all, got %v", gold, vals)
+		}
+
+		counts := map[int]int{}
+		for v, c := range g.Counts() {
+			counts[v] = c
+		}
+
+		goldCounts := map[int]int{1: 1, 2: 3, 3: 1}
+		if !reflect.DeepEqual(counts, goldCounts) {
+			t.Fatalf("Expected %v from counts, got %v", goldCounts, counts)
+		}
+	})
+	t.Run("Break", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		var vals []int
+		for v := range g.All() {
+			vals = append(vals, v)
+			if v == 2 {
+				break
+			}
+		}
+
+		if !reflect.DeepEqual(vals, []int{0, 1, 2}) {
+			t.Fatalf("Expected [0 1 2] before breaking, got %v", vals)
+		}
+
+		// every node has let go by the time the loop is done
+		tctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		if ok, err := g.DeleteCtx(tctx, 7); !ok || err != nil {
+			t.Fatalf("Expected a true result from delete after breaking, got %v, %v", ok, err)
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.InsertCtx(ctx, 1)
+		g.Close()
+
+		for v := range g.All() {
+			t.Fatalf("Expected nothing from a closed tree, got %d", v)
+		}
+	})
+}
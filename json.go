@@ -0,0 +1,278 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Codec turns the values in a tree into JSON and back, for MarshalJSON and
+// UnmarshalJSON. By default values go through encoding/json, which works for
+// most concrete types but can't decode an interface such as Comparer; a tree
+// of those needs a Codec that knows what's really in it.
+type Codec[T any] interface {
+	Encode(val T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// WithCodec sets the Codec a tree uses to write its values as JSON and read
+// them back. It has to be a Codec for the type of value the tree holds.
+func WithCodec[T any](c Codec[T]) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+// jsonCodec is the Codec used when none is given.
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Encode(val T) ([]byte, error) {
+	return json.Marshal(val)
+}
+
+func (jsonCodec[T]) Decode(data []byte) (T, error) {
+	var val T
+	err := json.Unmarshal(data, &val)
+	return val, err
+}
+
+// valueCodec returns the Codec the tree was given, or the default one.
+func (cfg *config[T]) valueCodec() (Codec[T], error) {
+	if cfg.codec == nil {
+		return jsonCodec[T]{}, nil
+	}
+
+	c, ok := cfg.codec.(Codec[T])
+	if !ok {
+		var zero T
+		return nil, fmt.Errorf("goroutree: codec %T can't be used for values of type %T", cfg.codec, zero)
+	}
+
+	return c, nil
+}
+
+// jsonNode is how a node is written out. Count, Red and Priority only mean
+// anything for a Multiset, RedBlack or Treap tree respectively, so they're
+// left out of the others.
+type jsonNode struct {
+	Value    json.RawMessage `json:"value"`
+	Count    int             `json:"count,omitempty"`
+	Red      bool            `json:"red,omitempty"`
+	Priority int64           `json:"priority,omitempty"`
+	Left     *jsonNode       `json:"left,omitempty"`
+	Right    *jsonNode       `json:"right,omitempty"`
+}
+
+// jsonCmd has a subtree write itself out as a jsonNode. It goes down the tree
+// the same way ctPrint does, so nothing changes while it's being written.
+type jsonCmd[T any] struct {
+	reschan chan jsonResult
+	codec   Codec[T]
+}
+
+func (c jsonCmd[T]) typ() cmdType {
+	return ctJSON
+}
+
+type jsonResult struct {
+	node *jsonNode
+	err  error
+}
+
+// replaceCmd is handled entirely by the manager, which shuts down the tree it
+// has and puts root in its place.
+type replaceCmd struct {
+	reschan chan struct{}
+	root    subtree
+}
+
+func (c replaceCmd) typ() cmdType {
+	return ctReplace
+}
+
+// MarshalJSON writes the tree out as it is right now, as nested objects with
+// the node's value under "value" and its children under "left" and "right".
+// An empty tree is null. A Multiset tree adds each node's "count", a RedBlack
+// tree marks red nodes with "red" and a Treap adds each node's "priority", so
+// UnmarshalJSON can put all of it back. Like Print, it holds every node still
+// until it's done.
+func (g *Goroutree[T]) MarshalJSON() ([]byte, error) {
+	codec, err := g.cfg.valueCodec()
+	if err != nil {
+		return nil, err
+	}
+
+	reschan := make(chan jsonResult, 1)
+	res, err := request(context.Background(), g, jsonCmd[T]{
+		reschan: reschan,
+		codec:   codec,
+	}, reschan)
+	if err != nil {
+		return nil, err
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	return json.Marshal(res.node)
+}
+
+// UnmarshalJSON replaces everything in the tree with the nodes described by
+// data, in the form MarshalJSON writes, spawning a node for each one in
+// exactly the shape given. The old nodes are shut down once the new ones are
+// swapped in, the same way as for Rebalance.
+//
+// g has to have been made by one of the constructors, since that's where its
+// ordering and options come from. The values must be in order from left to
+// right, or ErrNotSorted is returned and the tree is left as it was. Nothing
+// is checked against the tree's balancing rules, so the shape given should
+// already follow them.
+func (g *Goroutree[T]) UnmarshalJSON(data []byte) error {
+	if g.cfg == nil {
+		return errors.New("goroutree: UnmarshalJSON needs a tree made by a constructor")
+	}
+
+	codec, err := g.cfg.valueCodec()
+	if err != nil {
+		return err
+	}
+
+	var top *jsonNode
+	if err := json.Unmarshal(data, &top); err != nil {
+		return err
+	}
+
+	// every value is decoded and checked before any nodes are spawned, so
+	// there's nothing to clean up if it doesn't work out
+	var vals []T
+	var inorder func(jn *jsonNode) error
+	inorder = func(jn *jsonNode) error {
+		if jn == nil {
+			return nil
+		}
+
+		if err := inorder(jn.Left); err != nil {
+			return err
+		}
+
+		val, err := codec.Decode(jn.Value)
+		if err != nil {
+			return err
+		}
+
+		if len(vals) > 0 {
+			comparison, err := g.cfg.compare(vals[len(vals)-1], val)
+			if err != nil {
+				return err
+			}
+			if comparison >= 0 {
+				return ErrNotSorted
+			}
+		}
+		vals = append(vals, val)
+
+		return inorder(jn.Right)
+	}
+
+	if err := inorder(top); err != nil {
+		return err
+	}
+
+	var spawn func(jn *jsonNode) subtree
+	spawn = func(jn *jsonNode) subtree {
+		if jn == nil {
+			return subtree{}
+		}
+
+		left := spawn(jn.Left)
+
+		n := newNode(vals[0], nil, g.cfg)
+		vals = vals[1:]
+
+		n.red = g.cfg.balance == RedBlack && jn.Red
+		n.prio = jn.Priority
+		if g.cfg.multiset && jn.Count > 1 {
+			n.count = jn.Count
+		}
+
+		n.left = left
+		n.right = spawn(jn.Right)
+
+		return n.spawn()
+	}
+
+	root := spawn(top)
+
+	reschan := make(chan struct{}, 1)
+	if _, err := request(context.Background(), g, replaceCmd{reschan: reschan, root: root}, reschan); err != nil {
+		if root.ch != nil {
+			reschan := make(chan struct{})
+			root.ch <- closeCmd{reschan: reschan}
+			<-reschan
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (n *node[T]) json(c jsonCmd[T]) {
+	childcmd := jsonCmd[T]{
+		reschan: make(chan jsonResult),
+		codec:   c.codec,
+	}
+
+	var jn jsonNode
+	var err error
+
+	for _, side := range []struct {
+		child subtree
+		out   **jsonNode
+	}{
+		{n.left, &jn.Left},
+		{n.right, &jn.Right},
+	} {
+		if side.child.ch == nil {
+			continue
+		}
+
+		// the rest of the subtree still has to hear the command and let go,
+		// even once something has gone wrong
+		side.child.ch <- childcmd
+		res := <-childcmd.reschan
+		*side.out = res.node
+		if err == nil {
+			err = res.err
+		}
+	}
+
+	if err == nil {
+		jn.Value, err = c.codec.Encode(n.val)
+	}
+
+	if n.cfg.multiset {
+		jn.Count = n.count
+	}
+	jn.Red = n.cfg.balance == RedBlack && n.red
+	if n.cfg.balance == Treap {
+		jn.Priority = n.prio
+	}
+
+	c.reschan <- jsonResult{node: &jn, err: err}
+}
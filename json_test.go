@@ -0,0 +1,182 @@
+package goroutree_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// intCodec writes Int values as plain JSON numbers.
+type intCodec struct{}
+
+func (intCodec) Encode(val goroutree.Comparer) ([]byte, error) {
+	return json.Marshal(int(val.(goroutree.Int)))
+}
+
+func (intCodec) Decode(data []byte) (goroutree.Comparer, error) {
+	var i int
+	err := json.Unmarshal(data, &i)
+	return goroutree.Int(i), err
+}
+
+func TestJSON(t *testing.T) {
+	ctx := context.Background()
+
+	marshal := func(t *testing.T, g *goroutree.Goroutree[int]) string {
+		t.Helper()
+
+		data, err := json.Marshal(g)
+		if err != nil {
+			t.Fatalf("Expected no error from marshalling, got %v", err)
+		}
+
+		return string(data)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		if got := marshal(t, g); got != "null" {
+			t.Fatalf("Expected null, got %s", got)
+		}
+
+		g.InsertCtx(ctx, 1)
+		if err := json.Unmarshal([]byte("null"), g); err != nil {
+			t.Fatalf("Expected no error from unmarshalling, got %v", err)
+		}
+		checkLen(t, g, 0)
+	})
+	t.Run("Shape", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{2, 1, 4, 3} {
+			g.InsertCtx(ctx, i)
+		}
+
+		expected := `{"value":2,"left":{"value":1},"right":{"value":4,"left":{"value":3}}}`
+		if got := marshal(t, g); got != expected {
+			t.Fatalf("Expected %s, got %s", expected, got)
+		}
+	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, opts := range [][]goroutree.Option{
+			nil,
+			{goroutree.WithBalance(goroutree.AVL)},
+			{goroutree.WithBalance(goroutree.RedBlack)},
+			{goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(1)},
+			{goroutree.Multiset()},
+		} {
+			g := goroutree.NewOrdered[int](opts...)
+			for _, i := range []int{8, 3, 12, 1, 5, 3, 10, 14, 7, 2} {
+				g.InsertCtx(ctx, i)
+			}
+
+			data := marshal(t, g)
+
+			c := goroutree.NewOrdered[int](opts...)
+			if err := json.Unmarshal([]byte(data), c); err != nil {
+				t.Fatalf("Expected no error from unmarshalling, got %v", err)
+			}
+
+			if got := marshal(t, c); got != data {
+				t.Fatalf("Expected %s, got %s", data, got)
+			}
+			if got, expected := printed(t, c), printed(t, g); got != expected {
+				t.Fatalf("Expected the same tree:\n%s\ngot:\n%s", expected, got)
+			}
+
+			g.Close()
+			c.Close()
+		}
+	})
+	t.Run("Replace", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for i := 0; i < 20; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		data := `{"value":5,"left":{"value":3},"right":{"value":9}}`
+		if err := json.Unmarshal([]byte(data), g); err != nil {
+			t.Fatalf("Expected no error from unmarshalling, got %v", err)
+		}
+
+		checkOrder(t, g, []int{3, 5, 9})
+		checkRB(t, g)
+
+		// and it's an ordinary tree afterwards
+		for i := 10; i < 30; i++ {
+			g.InsertCtx(ctx, i)
+		}
+		checkRB(t, g)
+		checkLen(t, g, 23)
+	})
+	t.Run("NotSorted", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		g.InsertCtx(ctx, 1)
+
+		data := `{"value":5,"left":{"value":6}}`
+		if err := json.Unmarshal([]byte(data), g); err != goroutree.ErrNotSorted {
+			t.Fatalf("Expected ErrNotSorted, got %v", err)
+		}
+		checkOrder(t, g, []int{1})
+	})
+	t.Run("Codec", func(t *testing.T) {
+		g := goroutree.New(goroutree.WithCodec[goroutree.Comparer](intCodec{}))
+		defer g.Close()
+
+		data := `{"value":2,"left":{"value":1},"right":{"value":3}}`
+		if err := json.Unmarshal([]byte(data), g); err != nil {
+			t.Fatalf("Expected no error from unmarshalling, got %v", err)
+		}
+
+		if ok, _ := g.ContainsCtx(ctx, goroutree.Int(3)); !ok {
+			t.Fatal("Expected a true result from contains")
+		}
+
+		got, err := json.Marshal(g)
+		if err != nil || string(got) != data {
+			t.Fatalf("Expected %s, got %s, %v", data, got, err)
+		}
+
+		// there's no way to decode a Comparer without being told how
+		d := goroutree.New()
+		defer d.Close()
+
+		if err := json.Unmarshal([]byte(data), d); err == nil {
+			t.Fatal("Expected an error from unmarshalling without a codec")
+		}
+
+		// and a codec for the wrong type can't be used at all
+		w := goroutree.NewOrdered[int](goroutree.WithCodec[goroutree.Comparer](intCodec{}))
+		defer w.Close()
+
+		if _, err := json.Marshal(w); err == nil {
+			t.Fatal("Expected an error from marshalling with the wrong codec")
+		}
+	})
+	t.Run("ZeroValue", func(t *testing.T) {
+		var g goroutree.Goroutree[int]
+		if err := json.Unmarshal([]byte(`{"value":1}`), &g); err == nil {
+			t.Fatal("Expected an error from unmarshalling into a zero tree")
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, err := g.MarshalJSON(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from marshalling, got %v", err)
+		}
+		if err := g.UnmarshalJSON([]byte(`{"value":1}`)); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from unmarshalling, got %v", err)
+		}
+	})
+}
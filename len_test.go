@@ -0,0 +1,109 @@
+package goroutree_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func checkLen(t *testing.T, g interface{ Len() (int, error) }, expected int) {
+	t.Helper()
+
+	if l, err := g.Len(); l != expected || err != nil {
+		t.Fatalf("Expected a length of %d, got %d, %v", expected, l, err)
+	}
+}
+
+func TestLen(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		checkLen(t, g, 0)
+	})
+	t.Run("InsertDelete", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{7, 3, 11, 1, 5, 9, 13} {
+			g.InsertCtx(ctx, i)
+		}
+		checkLen(t, g, 7)
+
+		// duplicates and misses don't count
+		g.InsertCtx(ctx, 5)
+		g.DeleteCtx(ctx, 6)
+		checkLen(t, g, 7)
+
+		// leaf, one child and two children
+		g.DeleteCtx(ctx, 1)
+		checkLen(t, g, 6)
+		g.DeleteCtx(ctx, 3)
+		checkLen(t, g, 5)
+		g.DeleteCtx(ctx, 7)
+		checkLen(t, g, 4)
+
+		for _, i := range []int{5, 9, 11, 13} {
+			g.DeleteCtx(ctx, i)
+		}
+		checkLen(t, g, 0)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{5, 5, 5, 3} {
+			g.InsertCtx(ctx, i)
+		}
+		checkLen(t, g, 4)
+
+		g.DeleteCtx(ctx, 5)
+		checkLen(t, g, 3)
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, err := g.Len(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from len, got %v", err)
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		const workers = 8
+		const each = 200
+
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < each; i++ {
+					g.InsertCtx(ctx, (i*workers+w)*7919%(workers*each))
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		checkLen(t, g, workers*each)
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < each; i += 2 {
+					g.DeleteCtx(ctx, (i*workers+w)*7919%(workers*each))
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		checkLen(t, g, workers*each/2)
+	})
+}
@@ -0,0 +1,111 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"cmp"
+	"errors"
+)
+
+// ErrNotSorted is returned by FromSorted and LoadSorted when a value comes
+// after one that's larger than it.
+var ErrNotSorted = errors.New("goroutree: values are not sorted")
+
+// FromSorted creates a Goroutree of Comparer values holding values, which must
+// be in increasing order. Inserting sorted values one at a time into an
+// Unbalanced tree builds one long chain of nodes, and even a balanced tree
+// sends every one of them all the way down from the root; this builds the
+// nodes directly in a balanced shape instead, with separate subtrees built at
+// the same time. Repeated values are only added once, unless the Multiset
+// option is passed, in which case they're all counted.
+//
+// The tree that comes back works like any other created with the same options.
+// If two values can't be compared the error is returned, and ErrNotSorted is
+// returned if they are out of order.
+func FromSorted(values []Comparer, opts ...Option) (*Goroutree[Comparer], error) {
+	return fromSorted(values, func(a, b Comparer) (int, error) {
+		return a.Compare(b)
+	}, opts)
+}
+
+// FromSortedOrdered is the same as FromSorted for values that can be ordered
+// with the < operator.
+func FromSortedOrdered[T cmp.Ordered](values []T, opts ...Option) (*Goroutree[T], error) {
+	return FromSortedFunc(values, cmp.Compare[T], opts...)
+}
+
+// FromSortedFunc is the same as FromSorted for values ordered by the given
+// function, which follows the same rules as the one passed to NewFunc.
+func FromSortedFunc[T any](values []T, compare func(a, b T) int, opts ...Option) (*Goroutree[T], error) {
+	return fromSorted(values, func(a, b T) (int, error) {
+		return compare(a, b), nil
+	}, opts)
+}
+
+// LoadSorted is the same as FromSorted, but reads the values off a channel
+// until it's closed. None of the tree is built until then, since how it's laid
+// out depends on how many values there are.
+func LoadSorted(values <-chan Comparer, opts ...Option) (*Goroutree[Comparer], error) {
+	return FromSorted(drain(values), opts...)
+}
+
+// LoadSortedOrdered is the same as LoadSorted for values that can be ordered
+// with the < operator.
+func LoadSortedOrdered[T cmp.Ordered](values <-chan T, opts ...Option) (*Goroutree[T], error) {
+	return FromSortedOrdered(drain(values), opts...)
+}
+
+// LoadSortedFunc is the same as LoadSorted for values ordered by the given
+// function.
+func LoadSortedFunc[T any](values <-chan T, compare func(a, b T) int, opts ...Option) (*Goroutree[T], error) {
+	return FromSortedFunc(drain(values), compare, opts...)
+}
+
+func drain[T any](values <-chan T) []T {
+	var out []T
+	for v := range values {
+		out = append(out, v)
+	}
+
+	return out
+}
+
+func fromSorted[T any](values []T, compare func(a, b T) (int, error), opts []Option) (*Goroutree[T], error) {
+	cfg := newConfig(compare, opts)
+
+	entries := make([]entry[T], 0, len(values))
+	for i, v := range values {
+		if i > 0 {
+			c, err := compare(values[i-1], v)
+			if err != nil {
+				return nil, err
+			}
+			if c > 0 {
+				return nil, ErrNotSorted
+			}
+
+			if c == 0 {
+				if cfg.multiset {
+					entries[len(entries)-1].count++
+				}
+				continue
+			}
+		}
+
+		entries = append(entries, entry[T]{val: v, count: 1})
+	}
+
+	return start(cfg, build(entries, cfg)), nil
+}
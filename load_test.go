@@ -0,0 +1,148 @@
+package goroutree_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestFromSorted(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g, err := goroutree.FromSortedOrdered[int](nil)
+		if err != nil {
+			t.Fatalf("Expected no error from loading, got %v", err)
+		}
+		defer g.Close()
+
+		checkLen(t, g, 0)
+		if ok, _ := g.InsertCtx(ctx, 1); !ok {
+			t.Fatal("Expected a true result from inserting")
+		}
+	})
+	t.Run("Balanced", func(t *testing.T) {
+		var values []int
+		for i := 0; i < 15; i++ {
+			values = append(values, i)
+		}
+
+		g, _ := goroutree.FromSortedOrdered(values)
+		defer g.Close()
+
+		ref := goroutree.NewOrdered[int]()
+		defer ref.Close()
+
+		for _, i := range balancedOrder {
+			ref.InsertCtx(ctx, i)
+		}
+
+		if got, expected := printed(t, g), printed(t, ref); got != expected {
+			t.Fatalf("Expected a perfectly balanced tree:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Large", func(t *testing.T) {
+		// big enough that the builder splits the work between goroutines
+		var values []int
+		for i := 0; i < 5000; i++ {
+			values = append(values, i*2)
+		}
+
+		for _, b := range []goroutree.Balance{goroutree.Unbalanced, goroutree.AVL, goroutree.RedBlack, goroutree.Treap} {
+			g, err := goroutree.FromSortedOrdered(values, goroutree.WithBalance(b))
+			if err != nil {
+				t.Fatalf("Expected no error from loading, got %v", err)
+			}
+
+			switch b {
+			case goroutree.AVL:
+				checkAVL(t, g)
+			case goroutree.RedBlack:
+				checkRB(t, g)
+			default:
+				if h := shapeOf(t, g).height(); h != 13 {
+					t.Fatalf("Expected a height of 13, got %d", h)
+				}
+			}
+			checkOrder(t, g, values)
+
+			// and it's an ordinary tree afterwards
+			if ok, _ := g.InsertCtx(ctx, 3); !ok {
+				t.Fatal("Expected a true result from inserting")
+			}
+			if ok, _ := g.DeleteCtx(ctx, 4000); !ok {
+				t.Fatal("Expected a true result from deleting")
+			}
+			checkLen(t, g, 5000)
+
+			g.Close()
+		}
+	})
+	t.Run("Duplicates", func(t *testing.T) {
+		g, _ := goroutree.FromSortedOrdered([]int{1, 2, 2, 3, 3, 3})
+		defer g.Close()
+
+		checkOrder(t, g, []int{1, 2, 3})
+
+		m, _ := goroutree.FromSortedOrdered([]int{1, 2, 2, 3, 3, 3}, goroutree.Multiset())
+		defer m.Close()
+
+		checkOrder(t, m, []int{1, 2, 2, 3, 3, 3})
+		if c, _ := m.Count(3); c != 3 {
+			t.Fatalf("Expected a count of 3, got %d", c)
+		}
+	})
+	t.Run("NotSorted", func(t *testing.T) {
+		if _, err := goroutree.FromSortedOrdered([]int{1, 3, 2}); err != goroutree.ErrNotSorted {
+			t.Fatalf("Expected ErrNotSorted, got %v", err)
+		}
+	})
+	t.Run("Comparer", func(t *testing.T) {
+		g, err := goroutree.FromSorted([]goroutree.Comparer{goroutree.Int(1), goroutree.Int(2)})
+		if err != nil {
+			t.Fatalf("Expected no error from loading, got %v", err)
+		}
+		defer g.Close()
+
+		if ok, _ := g.ContainsCtx(ctx, goroutree.Int(2)); !ok {
+			t.Fatal("Expected a true result from contains")
+		}
+
+		if _, err := goroutree.FromSorted([]goroutree.Comparer{goroutree.Int(1), Str("a")}); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable, got %v", err)
+		}
+	})
+	t.Run("Channel", func(t *testing.T) {
+		values := make(chan goroutree.Comparer)
+		go func() {
+			for i := 0; i < 100; i++ {
+				values <- goroutree.Int(i)
+			}
+			close(values)
+		}()
+
+		g, err := goroutree.LoadSorted(values, goroutree.WithBalance(goroutree.RedBlack))
+		if err != nil {
+			t.Fatalf("Expected no error from loading, got %v", err)
+		}
+		defer g.Close()
+
+		checkLen(t, g, 100)
+		if v, ok, _ := g.Max(); v != goroutree.Int(99) || !ok {
+			t.Fatalf("Expected 99 from max, got %v, %v", v, ok)
+		}
+	})
+}
+
+func BenchmarkFromSorted(b *testing.B) {
+	values := make([]int, 100000)
+	for i := range values {
+		values[i] = i
+	}
+
+	for i := 0; i < b.N; i++ {
+		g, _ := goroutree.FromSortedOrdered(values)
+		g.Close()
+	}
+}
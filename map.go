@@ -0,0 +1,101 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"cmp"
+	"context"
+)
+
+// Map is an ordered map made of the same node goroutines as a Goroutree. Each
+// node owns a key and the value stored under it, and the two always move
+// around the tree together.
+type Map[K, V any] struct {
+	tree *Goroutree[K]
+}
+
+// NewMap creates a new empty Map with keys that can be ordered with the <
+// operator. Options such as WithBalance work the same as they do for a
+// Goroutree.
+func NewMap[K cmp.Ordered, V any](opts ...Option) *Map[K, V] {
+	return &Map[K, V]{tree: NewOrdered[K](opts...)}
+}
+
+// NewMapFunc creates a new empty Map with keys ordered by the given function,
+// which follows the same rules as the one passed to NewFunc.
+func NewMapFunc[K, V any](compare func(a, b K) int, opts ...Option) *Map[K, V] {
+	return &Map[K, V]{tree: NewFunc(compare, opts...)}
+}
+
+// Put stores val under key. If the key was already in the map, the value it
+// replaced is returned along with true. A key is only ever stored once, even
+// in a Map made with Multiset.
+func (m *Map[K, V]) Put(key K, val V) (V, bool, error) {
+	reschan := make(chan Result, 1)
+	res := m.tree.wait(context.Background(), insertCmd[K]{
+		reschan: reschan,
+		val:     key,
+		payload: val,
+		replace: true,
+	}, reschan)
+
+	// a fresh insert means there was nothing there before
+	if res.Ok || res.Err != nil {
+		var zero V
+		return zero, false, res.Err
+	}
+
+	return payloadOf[V](res), true, nil
+}
+
+// Get returns the value stored under key, and whether the key was found.
+func (m *Map[K, V]) Get(key K) (V, bool, error) {
+	reschan := make(chan Result, 1)
+	res := m.tree.wait(context.Background(), containsCmd[K]{
+		reschan: reschan,
+		val:     key,
+	}, reschan)
+
+	return payloadOf[V](res), res.Ok, res.Err
+}
+
+// Remove takes key out of the map and returns the value that was stored under
+// it, and whether the key was found.
+func (m *Map[K, V]) Remove(key K) (V, bool, error) {
+	reschan := make(chan Result, 1)
+	res := m.tree.wait(context.Background(), deleteCmd[K]{
+		reschan: reschan,
+		val:     key,
+	}, reschan)
+
+	return payloadOf[V](res), res.Ok, res.Err
+}
+
+// Rebalance rebuilds the map as a balanced tree. See Goroutree.Rebalance.
+func (m *Map[K, V]) Rebalance() error {
+	return m.tree.Rebalance()
+}
+
+// Close shuts down every node in the map. See Goroutree.Close.
+func (m *Map[K, V]) Close() error {
+	return m.tree.Close()
+}
+
+// payloadOf pulls the map value out of a result. A missing payload (or a nil
+// one stored for an interface type) comes back as the zero value.
+func payloadOf[V any](res Result) V {
+	v, _ := res.payload.(V)
+	return v
+}
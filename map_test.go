@@ -0,0 +1,133 @@
+package goroutree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestMap(t *testing.T) {
+	t.Run("PutGet", func(t *testing.T) {
+		m := goroutree.NewMap[int, string]()
+		defer m.Close()
+
+		if prev, ok, err := m.Put(4, "four"); ok || err != nil || prev != "" {
+			t.Fatalf("Expected no previous value from put, got %q, %v, %v", prev, ok, err)
+		}
+
+		if v, ok, err := m.Get(4); !ok || err != nil || v != "four" {
+			t.Fatalf("Expected \"four\" from get, got %q, %v, %v", v, ok, err)
+		}
+
+		if prev, ok, err := m.Put(4, "FOUR"); !ok || err != nil || prev != "four" {
+			t.Fatalf("Expected previous value \"four\" from put, got %q, %v, %v", prev, ok, err)
+		}
+
+		if v, ok, err := m.Get(4); !ok || err != nil || v != "FOUR" {
+			t.Fatalf("Expected \"FOUR\" from get, got %q, %v, %v", v, ok, err)
+		}
+
+		if v, ok, err := m.Get(5); ok || err != nil || v != "" {
+			t.Fatalf("Expected a miss from get, got %q, %v, %v", v, ok, err)
+		}
+	})
+	t.Run("Remove", func(t *testing.T) {
+		t.Run("Miss", func(t *testing.T) {
+			m := goroutree.NewMap[int, string]()
+			defer m.Close()
+
+			if v, ok, err := m.Remove(4); ok || err != nil || v != "" {
+				t.Fatalf("Expected a miss from remove, got %q, %v, %v", v, ok, err)
+			}
+		})
+		t.Run("Leaf", func(t *testing.T) {
+			m := goroutree.NewMap[int, string]()
+			defer m.Close()
+
+			m.Put(4, "four")
+			m.Put(3, "three")
+
+			if v, ok, err := m.Remove(3); !ok || err != nil || v != "three" {
+				t.Fatalf("Expected \"three\" from remove, got %q, %v, %v", v, ok, err)
+			}
+
+			if _, ok, _ := m.Get(3); ok {
+				t.Fatal("Expected a miss from get after remove")
+			}
+		})
+		t.Run("TwoChildren", func(t *testing.T) {
+			// Removing 4 pulls 5 up out of the right subtree. Its value needs to
+			// come along with it.
+			//
+			//     4
+			//   3   7
+			//      5
+			//       6
+			m := goroutree.NewMap[int, string]()
+			defer m.Close()
+
+			for _, k := range []int{4, 3, 7, 5, 6} {
+				m.Put(k, strings.Repeat("x", k))
+			}
+
+			if v, ok, err := m.Remove(4); !ok || err != nil || v != "xxxx" {
+				t.Fatalf("Expected \"xxxx\" from remove, got %q, %v, %v", v, ok, err)
+			}
+
+			for _, k := range []int{3, 5, 6, 7} {
+				if v, ok, err := m.Get(k); !ok || err != nil || v != strings.Repeat("x", k) {
+					t.Fatalf("Expected %q from get %d, got %q, %v, %v", strings.Repeat("x", k), k, v, ok, err)
+				}
+			}
+
+			if _, ok, _ := m.Get(4); ok {
+				t.Fatal("Expected a miss from get after remove")
+			}
+		})
+	})
+	t.Run("Func", func(t *testing.T) {
+		m := goroutree.NewMapFunc[goroutree.Int, int](func(a, b goroutree.Int) int {
+			i, _ := a.Compare(b)
+			return i
+		})
+		defer m.Close()
+
+		m.Put(goroutree.Int(1), 10)
+
+		if v, ok, err := m.Get(goroutree.Int(1)); !ok || err != nil || v != 10 {
+			t.Fatalf("Expected 10 from get, got %d, %v, %v", v, ok, err)
+		}
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		m := goroutree.NewMap[int, string](goroutree.Multiset())
+		defer m.Close()
+
+		m.Put(1, "a")
+
+		if prev, ok, err := m.Put(1, "b"); !ok || err != nil || prev != "a" {
+			t.Fatalf("Expected previous value \"a\" from put, got %q, %v, %v", prev, ok, err)
+		}
+
+		if v, ok, err := m.Get(1); !ok || err != nil || v != "b" {
+			t.Fatalf("Expected \"b\" from get, got %q, %v, %v", v, ok, err)
+		}
+
+		// the key is only there once, so one remove takes it out
+		if v, ok, err := m.Remove(1); !ok || err != nil || v != "b" {
+			t.Fatalf("Expected \"b\" from remove, got %q, %v, %v", v, ok, err)
+		}
+
+		if v, ok, err := m.Get(1); ok || err != nil || v != "" {
+			t.Fatalf("Expected a miss from get, got %q, %v, %v", v, ok, err)
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		m := goroutree.NewMap[int, string]()
+		m.Close()
+
+		if _, _, err := m.Put(4, "four"); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from put, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+// valueResult is sent back by commands that answer with a value out of the
+// tree rather than a yes or no. ok is false if there was no such value.
+type valueResult[T any] struct {
+	val T
+	ok  bool
+	err error
+}
+
+// peekCmd finds the smallest value in the tree, or the largest if max is set,
+// without changing anything.
+type peekCmd[T any] struct {
+	reschan chan valueResult[T]
+	max     bool
+}
+
+func (c peekCmd[T]) typ() cmdType {
+	return ctPeek
+}
+
+// popCmd is handled by the manager, which turns it into an extractMinCmd sent
+// to the root.
+type popCmd[T any] struct {
+	reschan chan valueResult[T]
+	max     bool
+}
+
+func (c popCmd[T]) typ() cmdType {
+	return ctPop
+}
+
+// Min returns the smallest value in the tree. The bool is false if the tree is
+// empty.
+func (g *Goroutree[T]) Min() (T, bool, error) {
+	return g.peek(false)
+}
+
+// Max returns the largest value in the tree. The bool is false if the tree is
+// empty.
+func (g *Goroutree[T]) Max() (T, bool, error) {
+	return g.peek(true)
+}
+
+// PopMin removes the smallest value from the tree and returns it. The bool is
+// false if the tree is empty. In a Multiset tree only one occurrence of the
+// value is removed. Together with Insert this lets the tree be used as a
+// concurrent priority queue.
+func (g *Goroutree[T]) PopMin() (T, bool, error) {
+	return g.pop(false)
+}
+
+// PopMax removes the largest value from the tree and returns it, the same way
+// PopMin does for the smallest.
+func (g *Goroutree[T]) PopMax() (T, bool, error) {
+	return g.pop(true)
+}
+
+func (g *Goroutree[T]) peek(max bool) (T, bool, error) {
+	reschan := make(chan valueResult[T], 1)
+	res, err := request(context.Background(), g, peekCmd[T]{
+		reschan: reschan,
+		max:     max,
+	}, reschan)
+
+	return res.val, res.ok, err
+}
+
+func (g *Goroutree[T]) pop(max bool) (T, bool, error) {
+	reschan := make(chan valueResult[T], 1)
+	res, err := request(context.Background(), g, popCmd[T]{
+		reschan: reschan,
+		max:     max,
+	}, reschan)
+
+	return res.val, res.ok, err
+}
+
+func (n *node[T]) peek(c peekCmd[T]) {
+	child := n.left
+	if c.max {
+		child = n.right
+	}
+
+	if child.ch != nil {
+		child.ch <- c
+		return
+	}
+
+	c.reschan <- valueResult[T]{val: n.val, ok: true}
+}
@@ -0,0 +1,116 @@
+package goroutree_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestMinMax(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		if _, ok, err := g.Min(); ok || err != nil {
+			t.Fatalf("Expected nothing from min, got %v, %v", ok, err)
+		}
+		if _, ok, err := g.Max(); ok || err != nil {
+			t.Fatalf("Expected nothing from max, got %v, %v", ok, err)
+		}
+		if _, ok, err := g.PopMin(); ok || err != nil {
+			t.Fatalf("Expected nothing from pop min, got %v, %v", ok, err)
+		}
+		if _, ok, err := g.PopMax(); ok || err != nil {
+			t.Fatalf("Expected nothing from pop max, got %v, %v", ok, err)
+		}
+	})
+	t.Run("Peek", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{7, 3, 11, 1, 5, 9, 13} {
+			g.InsertCtx(ctx, i)
+		}
+
+		if v, ok, err := g.Min(); v != 1 || !ok || err != nil {
+			t.Fatalf("Expected 1 from min, got %d, %v, %v", v, ok, err)
+		}
+		if v, ok, err := g.Max(); v != 13 || !ok || err != nil {
+			t.Fatalf("Expected 13 from max, got %d, %v, %v", v, ok, err)
+		}
+
+		checkLen(t, g, 7)
+	})
+	t.Run("PopRoot", func(t *testing.T) {
+		// the root is the smallest value, so popping it promotes its right child
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range []int{1, 3, 2} {
+			g.InsertCtx(ctx, i)
+		}
+
+		if v, ok, err := g.PopMin(); v != 1 || !ok || err != nil {
+			t.Fatalf("Expected 1 from pop min, got %d, %v, %v", v, ok, err)
+		}
+		if v, ok, err := g.PopMax(); v != 3 || !ok || err != nil {
+			t.Fatalf("Expected 3 from pop max, got %d, %v, %v", v, ok, err)
+		}
+		if v, ok, err := g.PopMax(); v != 2 || !ok || err != nil {
+			t.Fatalf("Expected 2 from pop max, got %d, %v, %v", v, ok, err)
+		}
+		if _, ok, _ := g.PopMin(); ok {
+			t.Fatal("Expected nothing from pop min on an emptied tree")
+		}
+
+		checkLen(t, g, 0)
+	})
+	t.Run("PriorityQueue", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		vals := []int{50, 20, 80, 10, 30, 70, 90, 25, 35, 60}
+		for _, i := range vals {
+			g.InsertCtx(ctx, i)
+		}
+
+		last := -1
+		for range vals {
+			v, ok, err := g.PopMin()
+			if !ok || err != nil {
+				t.Fatalf("Expected a value from pop min, got %v, %v", ok, err)
+			}
+			if v <= last {
+				t.Fatalf("Expected values in increasing order, got %d after %d", v, last)
+			}
+			last = v
+		}
+
+		checkLen(t, g, 0)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{5, 2, 2} {
+			g.InsertCtx(ctx, i)
+		}
+
+		for _, expected := range []int{2, 2, 5} {
+			if v, ok, _ := g.PopMin(); v != expected || !ok {
+				t.Fatalf("Expected %d from pop min, got %d, %v", expected, v, ok)
+			}
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, _, err := g.PopMin(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from pop min, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package goroutree_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestMultiset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Count", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{5, 3, 5, 5, 7} {
+			if ok, err := g.InsertCtx(ctx, i); !ok || err != nil {
+				t.Fatalf("Expected a true result from inserting %d, got %v, %v", i, ok, err)
+			}
+		}
+
+		for v, count := range map[int]int{5: 3, 3: 1, 7: 1, 4: 0} {
+			if c, err := g.Count(v); c != count || err != nil {
+				t.Fatalf("Expected a count of %d for %d, got %d, %v", count, v, c, err)
+			}
+		}
+	})
+	t.Run("Delete", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		g.InsertCtx(ctx, 5)
+		g.InsertCtx(ctx, 5)
+
+		if ok, err := g.DeleteCtx(ctx, 5); !ok || err != nil {
+			t.Fatalf("Expected a true result from delete, got %v, %v", ok, err)
+		}
+		if ok, _ := g.ContainsCtx(ctx, 5); !ok {
+			t.Fatal("Expected the value to still be there after one delete")
+		}
+		if ok, err := g.DeleteCtx(ctx, 5); !ok || err != nil {
+			t.Fatalf("Expected a true result from delete, got %v, %v", ok, err)
+		}
+		if ok, _ := g.ContainsCtx(ctx, 5); ok {
+			t.Fatal("Expected the value to be gone after the last delete")
+		}
+		if ok, _ := g.DeleteCtx(ctx, 5); ok {
+			t.Fatal("Expected a false result from deleting a missing value")
+		}
+	})
+	t.Run("SuccessorKeepsCount", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{4, 3, 6, 5, 5} {
+			g.InsertCtx(ctx, i)
+		}
+
+		// 4 has two children, so 5 gets pulled up into its place
+		g.DeleteCtx(ctx, 4)
+
+		if c, _ := g.Count(5); c != 2 {
+			t.Fatalf("Expected a count of 2 for 5, got %d", c)
+		}
+
+		structreschan := make(chan struct{})
+		buf := &bytes.Buffer{}
+
+		g.Print(structreschan, buf)
+		<-structreschan
+
+		t.Logf("Printed tree: \n%s", buf.String())
+
+		gold := " 3 x1\n5 x2\n 6 x1\n"
+		if buf.String() != gold {
+			t.Fatalf("Expected printed tree to be \"%s\" but got %s", gold, buf.String())
+		}
+	})
+	t.Run("Set", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		g.InsertCtx(ctx, 5)
+		g.InsertCtx(ctx, 5)
+
+		if c, _ := g.Count(5); c != 1 {
+			t.Fatalf("Expected a count of 1 for 5, got %d", c)
+		}
+	})
+}
@@ -0,0 +1,483 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// node is the state owned by a single node goroutine. Nothing in here is ever
+// touched by any other goroutine; everything else talks to the node through
+// its cmdchan.
+type node[T any] struct {
+	cfg *config[T]
+
+	// cmdchan will be a stream of commands to be done in this node
+	cmdchan     chan cmd
+	left, right subtree
+
+	// acks from children come back here. Only one command at a time is ever
+	// waiting on a child, so one channel is enough.
+	ackchan chan subtree
+
+	// the value this node owns, and whatever is stored with it in a Map
+	val     T
+	payload any
+
+	// how many times val has been inserted. Always 1 unless the tree is a
+	// multiset.
+	count int
+
+	// the colour of the link from the parent in a red-black tree. New nodes
+	// start out red.
+	red bool
+
+	// the priority of val in a Treap. It goes wherever val goes.
+	prio int64
+}
+
+// newNode sets up a node that owns a value. Nothing is running yet, so its
+// fields can still be changed before it is spawned.
+func newNode[T any](val T, payload any, cfg *config[T]) *node[T] {
+	n := &node[T]{
+		cfg:     cfg,
+		cmdchan: make(chan cmd),
+		ackchan: make(chan subtree),
+		val:     val,
+		payload: payload,
+		count:   1,
+		red:     true,
+	}
+
+	if cfg.balance == Treap {
+		n.prio = cfg.priority()
+	}
+
+	return n
+}
+
+// spawn starts the node's goroutine and returns what its parent needs to know
+// about it. After this the node must only be reached through its cmdchan.
+func (n *node[T]) spawn() subtree {
+	st := n.subtree()
+	go n.run()
+	return st
+}
+
+// run is the logic that each node runs. Essentially it is an infinite loop
+// that responds to messages sent on its command channel. It then decides to
+// either act on that message or pass it on down the tree. It returns when the
+// node is removed from the tree or the tree is closed.
+func (n *node[T]) run() {
+	for cm := range n.cmdchan {
+		switch cm.typ() {
+		case ctInsert:
+			n.insert(cm.(insertCmd[T]))
+
+		case ctContains:
+			n.contains(cm.(containsCmd[T]))
+
+		case ctDelete:
+			if n.delete(cm.(deleteCmd[T])) {
+				return
+			}
+
+		case ctExtractMin:
+			if n.extractMin(cm.(extractMinCmd[T])) {
+				return
+			}
+
+		case ctBatch:
+			if n.batch(cm.(batchCmd[T])) {
+				return
+			}
+
+		case ctPeek:
+			n.peek(cm.(peekCmd[T]))
+
+		case ctWalk:
+			n.walk(cm.(walkCmd[T]))
+
+		case ctBound:
+			n.bound(cm.(boundCmd[T]))
+
+		case ctRank:
+			n.rank(cm.(rankCmd[T]))
+
+		case ctSelect:
+			n.selectAt(cm.(selectCmd[T]))
+
+		case ctRotate:
+			n.rotateAt(cm.(rotateCmd))
+
+		case ctPivot:
+			n.pivot(cm.(pivotCmd[T]))
+
+		case ctFlip:
+			n.flip(cm.(flipCmd))
+
+		case ctPrint:
+			n.print(cm.(printCmd))
+
+		case ctCopy:
+			n.copy(cm.(copyCmd[T]))
+
+		case ctDOT:
+			n.dot(cm.(dotCmd))
+
+		case ctJSON:
+			n.json(cm.(jsonCmd[T]))
+
+		case ctClose:
+			n.close(cm.(closeCmd))
+			return
+
+		default:
+			panic(fmt.Sprintf("UNEXPECTED COMMAND: %#v", cm))
+		}
+	}
+}
+
+// subtree describes this node and everything under it, for sending back up to
+// the parent.
+func (n *node[T]) subtree() subtree {
+	return subtree{
+		ch:     n.cmdchan,
+		size:   n.count + n.left.size + n.right.size,
+		height: 1 + max(n.left.height, n.right.height),
+		lean:   n.left.height - n.right.height,
+
+		red:     n.red,
+		leftRed: n.left.red,
+
+		prio: n.prio,
+	}
+}
+
+// forward sends a command that might change the shape of the tree down to one
+// of the children and waits for the child to report back what's in its spot
+// now. The command's ack needs to already be set to n.ackchan.
+func (n *node[T]) forward(child *subtree, c cmd) {
+	child.ch <- c
+	*child = <-n.ackchan
+}
+
+func (n *node[T]) insert(c insertCmd[T]) {
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		// the parent is still waiting to hear back, so it has to be told
+		// nothing changed before the caller gets the error.
+		c.ack <- n.subtree()
+		c.reschan <- Result{Err: err}
+		return
+	}
+
+	if comparison == 0 {
+		res := Result{payload: n.payload}
+
+		// a Map keeps one value per key, so a Put replaces it even in a
+		// Multiset tree rather than counting the key again
+		if c.replace {
+			n.payload = c.payload
+		} else if n.cfg.multiset {
+			n.count++
+			res = Result{Ok: true}
+		}
+
+		ack := n.subtree()
+		if res.Ok {
+			ack = n.touched()
+		}
+
+		c.ack <- ack
+		c.reschan <- res
+		return
+	}
+
+	// left branch if smaller, right branch if bigger
+	child := &n.left
+	if comparison > 0 {
+		child = &n.right
+	}
+
+	// if there's no node there yet, the value goes there
+	if child.ch == nil {
+		*child = newNode(c.val, c.payload, n.cfg).spawn()
+		child.splay = splayTop
+		n.rebalance()
+		n.paintRoot(c.root)
+
+		c.ack <- n.report(child, c.root)
+		c.reschan <- Result{Ok: true}
+		return
+	}
+
+	// otherwise send it down.
+	parentack, root := c.ack, c.root
+	c.ack, c.root = n.ackchan, false
+	n.forward(child, c)
+	n.rebalance()
+	n.paintRoot(root)
+
+	parentack <- n.report(child, root)
+}
+
+func (n *node[T]) contains(c containsCmd[T]) {
+	if c.ack != nil {
+		n.containsSplay(c)
+		return
+	}
+
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		c.reschan <- Result{Err: err}
+		return
+	}
+
+	if comparison == 0 {
+		c.reschan <- Result{Ok: true, payload: n.payload, count: n.count}
+		return
+	}
+
+	// Go right if the value is bigger,
+	// left if smaller
+	if comparison > 0 && n.right.ch != nil {
+		n.right.ch <- c
+		return
+	}
+
+	if comparison < 0 && n.left.ch != nil {
+		n.left.ch <- c
+		return
+	}
+
+	// if we get here, the value does not exist in the tree
+	c.reschan <- Result{}
+}
+
+// delete returns true if this node has removed itself from the tree.
+func (n *node[T]) delete(c deleteCmd[T]) bool {
+	if n.cfg.balance == RedBlack {
+		return n.deleteRB(c)
+	}
+
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		c.ack <- n.subtree()
+		c.reschan <- Result{Err: err}
+		return false
+	}
+
+	// if a match, delete this node.
+	if comparison == 0 {
+
+		// in a multiset the node only goes away with its last occurrence
+		if n.count > 1 {
+			n.count--
+			c.ack <- n.subtree()
+			c.reschan <- Result{Ok: true}
+			return false
+		}
+
+		// if this is a leaf node with no children, it just returns
+		if n.left.ch == nil && n.right.ch == nil {
+			// tell the parent to forget about this node
+			c.ack <- subtree{}
+			c.reschan <- Result{Ok: true, payload: n.payload}
+			return true
+		}
+
+		// one child, promote it to current position by telling the parent.
+		// we know at this point that one is not nil, so this checks if we have
+		// one and only one not nil child.
+		if n.left.ch == nil || n.right.ch == nil {
+
+			promoted := n.left
+			if n.right.ch != nil {
+				promoted = n.right
+			}
+
+			// promote child
+			c.ack <- promoted
+			c.reschan <- Result{Ok: true, payload: n.payload}
+			return true
+		}
+
+		// At this point, we need to substitute the current node with either the
+		// maximum node on the left subtree or the minimum node on the right subtree.
+		// For simplicity, this implementation always chooses to pull the minimum node
+		// out of the right subtree.
+		// The pattern is to send a message down the right subtree to find the minimum
+		// node. Once it's found, it will have either one child or none. In this special
+		// case, the node that is found will take care of removing itself and send its
+		// value back up the tree. TO make things simpler, this node will simply take
+		// the value and assign it as its owned value. I could do some trickery with
+		// reassigning channels all over the place to physically transplant that other
+		// node to this position, but that just seems silly to do if I can get away with
+		// just taking ownership of that value. Whatever is stored with the value
+		// comes along with it.
+
+		reschan := make(chan subtreeMinResponse[T])
+		n.right.ch <- extractMinCmd[T]{reschan: reschan}
+
+		res := <-reschan
+		removed := n.payload
+		n.val = res.val
+		n.payload = res.payload
+		n.count = res.count
+		n.prio = res.prio
+		n.right = res.rest
+		n.rebalance()
+
+		c.ack <- n.subtree()
+		c.reschan <- Result{Ok: true, payload: removed}
+		return false
+	}
+
+	var child *subtree
+	if comparison > 0 && n.right.ch != nil {
+		child = &n.right
+	} else if comparison < 0 && n.left.ch != nil {
+		child = &n.left
+	}
+
+	if child == nil {
+		// if we get here, the value does not exist in the tree
+		c.ack <- n.subtree()
+		c.reschan <- Result{}
+		return false
+	}
+
+	// hold on to our own parent's ack channel, pass the command down and
+	// wait to hear whether the child went away.
+	parentack := c.ack
+	c.ack = n.ackchan
+	n.forward(child, c)
+	n.rebalance()
+
+	parentack <- n.subtree()
+	return false
+}
+
+// extractMin returns true if this node was the minimum (or maximum) and has
+// removed itself from the tree.
+func (n *node[T]) extractMin(c extractMinCmd[T]) bool {
+	if n.cfg.balance == RedBlack {
+		return n.extractMinRB(c)
+	}
+
+	child, other := &n.left, n.right
+	if c.max {
+		child, other = &n.right, n.left
+	}
+
+	// Keep heading left (or right) until there's nowhere left to go, then
+	// patch up that side on the way back.
+	if child.ch != nil {
+		reschan := make(chan subtreeMinResponse[T])
+		child.ch <- extractMinCmd[T]{
+			reschan: reschan,
+			max:     c.max,
+			one:     c.one,
+		}
+
+		res := <-reschan
+		*child = res.rest
+		n.rebalance()
+
+		res.rest = n.subtree()
+		c.reschan <- res
+		return false
+	}
+
+	if c.one && n.count > 1 {
+		n.count--
+		c.reschan <- subtreeMinResponse[T]{
+			val:     n.val,
+			payload: n.payload,
+			count:   1,
+			rest:    n.subtree(),
+		}
+
+		return false
+	}
+
+	// this is the minimum node. Send back the value and have the parent
+	// replace this node with whatever is on the other side. An empty subtree
+	// is fine here, so no check
+	c.reschan <- subtreeMinResponse[T]{
+		val:     n.val,
+		payload: n.payload,
+		count:   n.count,
+		prio:    n.prio,
+		rest:    other,
+	}
+
+	return true
+}
+
+func (n *node[T]) print(c printCmd) {
+	// Inorder printing traversal of the tree
+
+	// make a new command for the children. FOR THE CHILDREN.
+	// Each child gets the command and a chance to finish its work before
+	// this node mvoes on. This means that printing the tree is basically
+	// a blocking operation in which no other operations can be done.
+	childcmd := c
+	childcmd.reschan = make(chan struct{})
+	childcmd.level++
+
+	if n.left.ch != nil {
+		n.left.ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	// no this is not very efficient, but this is for debugging
+	line := fmt.Sprintf("%s%v", bytes.Repeat([]byte(" "), c.level), n.val)
+	if n.cfg.multiset {
+		line += fmt.Sprintf(" x%d", n.count)
+	}
+	if n.cfg.balance == RedBlack && n.red {
+		line += " red"
+	}
+	io.WriteString(c.w, line+"\n")
+
+	if n.right.ch != nil {
+		n.right.ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	c.reschan <- struct{}{}
+}
+
+func (n *node[T]) close(c closeCmd) {
+	// Shut down the children first so the whole subtree is gone by
+	// the time the parent hears back.
+	childcmd := closeCmd{reschan: make(chan struct{})}
+
+	if n.left.ch != nil {
+		n.left.ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	if n.right.ch != nil {
+		n.right.ch <- childcmd
+		<-childcmd.reschan
+	}
+
+	c.reschan <- struct{}{}
+}
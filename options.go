@@ -0,0 +1,90 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// Option changes how a tree behaves. Options are passed to New, NewOrdered or
+// NewFunc and are fixed for the life of the tree.
+type Option func(*options)
+
+type options struct {
+	multiset bool
+	balance  Balance
+
+	seed   int64
+	seeded bool
+
+	// a Codec[T], for whatever T the tree holds
+	codec any
+}
+
+// Multiset turns the tree into a bag: each node keeps a count of how many
+// times its value has been inserted. Insert always succeeds and bumps the
+// count, Delete lowers it and only removes the node once it hits zero, and
+// Count reports it.
+func Multiset() Option {
+	return func(o *options) {
+		o.multiset = true
+	}
+}
+
+// Balance picks how, if at all, a tree keeps itself balanced as values go in
+// and come out.
+type Balance int
+
+const (
+	// Unbalanced never rotates. Values end up wherever the order of inserts
+	// puts them, so sorted inserts build one long chain of nodes.
+	Unbalanced Balance = iota
+
+	// AVL keeps the heights of the two sides of every node within one of each
+	// other, rotating on the way back up from every insert and delete.
+	AVL
+
+	// RedBlack keeps the tree as a left-leaning red-black tree. It allows the
+	// tree to get a bit deeper than AVL does but needs fewer rotations to
+	// keep it that way, especially when deleting.
+	RedBlack
+
+	// Treap gives every node a random priority when it is created and keeps
+	// higher priorities above lower ones. That doesn't put any hard limit on
+	// how deep the tree gets, but it is shallow on average no matter what
+	// order values come in, and it takes less work to keep that way.
+	Treap
+
+	// Splay moves every value that is found, inserted or deleted up to the
+	// root, so values that are used often stay near the top. That means
+	// Contains changes the shape of the tree too, so lookups can no longer
+	// run down the tree at the same time as each other.
+	Splay
+)
+
+// WithBalance picks the balancing scheme for the tree. The default is
+// Unbalanced.
+func WithBalance(b Balance) Option {
+	return func(o *options) {
+		o.balance = b
+	}
+}
+
+// WithSeed seeds the random numbers a Treap uses for node priorities, so the
+// same values inserted in the same order always end up in the same shape. By
+// default the seed is random. It makes no difference to the other kinds of
+// tree.
+func WithSeed(seed int64) Option {
+	return func(o *options) {
+		o.seed = seed
+		o.seeded = true
+	}
+}
@@ -0,0 +1,113 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+// Both of these lean on the subtree sizes each node keeps for its children,
+// which every command that changes the shape of the tree keeps up to date on
+// its way back up. That way each one only needs to go down a single path.
+
+// rankCmd counts the values smaller than val. below is how many have been
+// passed on the way down so far.
+type rankCmd[T any] struct {
+	reschan chan Result
+	val     T
+	below   int
+}
+
+func (c rankCmd[T]) typ() cmdType {
+	return ctRank
+}
+
+// selectCmd finds the k-th smallest value in the subtree it's sent to.
+type selectCmd[T any] struct {
+	reschan chan valueResult[T]
+	k       int
+}
+
+func (c selectCmd[T]) typ() cmdType {
+	return ctSelect
+}
+
+// Rank returns how many values in the tree are smaller than val, whether or not
+// val is in the tree itself. In a Multiset tree every occurrence counts.
+func (g *Goroutree[T]) Rank(val T) (int, error) {
+	reschan := make(chan Result, 1)
+	res := g.wait(context.Background(), rankCmd[T]{
+		reschan: reschan,
+		val:     val,
+	}, reschan)
+
+	return res.count, res.Err
+}
+
+// Select returns the k-th smallest value in the tree, counting from 0. The bool
+// is false if k is out of range. In a Multiset tree a value takes up as many
+// spots as it has occurrences.
+func (g *Goroutree[T]) Select(k int) (T, bool, error) {
+	reschan := make(chan valueResult[T], 1)
+	res, err := request(context.Background(), g, selectCmd[T]{
+		reschan: reschan,
+		k:       k,
+	}, reschan)
+
+	return res.val, res.ok, err
+}
+
+func (n *node[T]) rank(c rankCmd[T]) {
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		c.reschan <- Result{Err: err}
+		return
+	}
+
+	if comparison == 0 {
+		c.reschan <- Result{Ok: true, count: c.below + n.left.size}
+		return
+	}
+
+	next := n.left.ch
+	if comparison > 0 {
+		// everything on the left and this node itself are smaller
+		c.below += n.left.size + n.count
+		next = n.right.ch
+	}
+
+	if next != nil {
+		next <- c
+		return
+	}
+
+	c.reschan <- Result{count: c.below}
+}
+
+func (n *node[T]) selectAt(c selectCmd[T]) {
+	if c.k < n.left.size {
+		n.left.ch <- c
+		return
+	}
+
+	c.k -= n.left.size
+	if c.k < n.count {
+		c.reschan <- valueResult[T]{val: n.val, ok: true}
+		return
+	}
+
+	// the manager checked k against the size of the whole tree, so there has
+	// to be something on the right
+	c.k -= n.count
+	n.right.ch <- c
+}
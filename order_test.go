@@ -0,0 +1,94 @@
+package goroutree_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func checkOrder(t *testing.T, g *goroutree.Goroutree[int], sorted []int) {
+	t.Helper()
+
+	for k, v := range sorted {
+		if got, ok, err := g.Select(k); got != v || !ok || err != nil {
+			t.Fatalf("Expected %d from select %d, got %d, %v, %v", v, k, got, ok, err)
+		}
+
+		// duplicates in a multiset share the rank of their first spot
+		first := slices.Index(sorted, v)
+		if r, err := g.Rank(v); r != first || err != nil {
+			t.Fatalf("Expected rank %d for %d, got %d, %v", first, v, r, err)
+		}
+	}
+
+	if _, ok, _ := g.Select(len(sorted)); ok {
+		t.Fatalf("Expected nothing from select %d", len(sorted))
+	}
+	if _, ok, _ := g.Select(-1); ok {
+		t.Fatal("Expected nothing from select -1")
+	}
+}
+
+func TestOrderStatistics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		checkOrder(t, g, nil)
+
+		if r, err := g.Rank(5); r != 0 || err != nil {
+			t.Fatalf("Expected rank 0 in an empty tree, got %d, %v", r, err)
+		}
+	})
+	t.Run("Balanced", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i*10)
+		}
+
+		checkOrder(t, g, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100, 110, 120, 130, 140})
+
+		// values that aren't in the tree still have a rank
+		for v, r := range map[int]int{-5: 0, 5: 1, 75: 8, 1000: 15} {
+			if got, _ := g.Rank(v); got != r {
+				t.Fatalf("Expected rank %d for %d, got %d", r, v, got)
+			}
+		}
+	})
+	t.Run("AfterDeletes", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		// a leaf, a node with two children, the root and a promoted child
+		for _, i := range []int{0, 3, 7, 1} {
+			g.DeleteCtx(ctx, i)
+		}
+		g.PopMax()
+
+		checkOrder(t, g, []int{2, 4, 5, 6, 8, 9, 10, 11, 12, 13})
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{5, 3, 5, 8} {
+			g.InsertCtx(ctx, i)
+		}
+
+		checkOrder(t, g, []int{3, 5, 5, 8})
+
+		if r, _ := g.Rank(8); r != 3 {
+			t.Fatalf("Expected rank 3 for 8, got %d", r)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+// rebalanceCmd is handled entirely by the manager.
+type rebalanceCmd struct {
+	reschan chan struct{}
+}
+
+func (c rebalanceCmd) typ() cmdType {
+	return ctRebalance
+}
+
+// Rebalance rebuilds the tree as a balanced one holding the same values, which
+// is useful after loading a lot of values into an Unbalanced tree. The new
+// nodes are swapped in for the old ones all at once and the old ones are shut
+// down. Everything sent to the tree while this is going on waits for it to
+// finish and then runs against the new nodes.
+func (g *Goroutree[T]) Rebalance() error {
+	reschan := make(chan struct{}, 1)
+	_, err := request(context.Background(), g, rebalanceCmd{reschan: reschan}, reschan)
+	return err
+}
+
+// snapshot collects everything under root, in order. The walk holds on to the
+// whole subtree while it runs, so it's a consistent copy.
+func snapshot[T any](root subtree) []entry[T] {
+	out := make(chan entry[T])
+	reschan := make(chan error, 1)
+
+	root.ch <- walkCmd[T]{
+		reschan: reschan,
+		out:     out,
+	}
+
+	var entries []entry[T]
+	for {
+		select {
+		case e := <-out:
+			entries = append(entries, e)
+
+		case <-reschan:
+			// there are no bounds to compare against, so no error either
+			return entries
+		}
+	}
+}
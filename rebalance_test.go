@@ -0,0 +1,205 @@
+package goroutree_test
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestRebalance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		if err := g.Rebalance(); err != nil {
+			t.Fatalf("Expected no error from rebalance, got %v", err)
+		}
+		checkLen(t, g, 0)
+	})
+	t.Run("Sorted", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for i := 0; i < 15; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		if h := shapeOf(t, g).height(); h != 15 {
+			t.Fatalf("Expected sorted inserts to make a chain 15 deep, got %d", h)
+		}
+
+		if err := g.Rebalance(); err != nil {
+			t.Fatalf("Expected no error from rebalance, got %v", err)
+		}
+
+		ref := goroutree.NewOrdered[int]()
+		defer ref.Close()
+
+		for _, i := range balancedOrder {
+			ref.InsertCtx(ctx, i)
+		}
+
+		if got, expected := printed(t, g), printed(t, ref); got != expected {
+			t.Fatalf("Expected a perfectly balanced tree:\n%s\ngot:\n%s", expected, got)
+		}
+
+		// and it still works like a tree afterwards
+		g.InsertCtx(ctx, 15)
+		g.DeleteCtx(ctx, 7)
+		checkOrder(t, g, []int{0, 1, 2, 3, 4, 5, 6, 8, 9, 10, 11, 12, 13, 14, 15})
+	})
+	t.Run("RedBlack", func(t *testing.T) {
+		// every size up to here needs a different mix of 2-nodes and 3-nodes
+		for size := 1; size <= 64; size++ {
+			g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+
+			var expected []int
+			for i := 0; i < size; i++ {
+				g.InsertCtx(ctx, i)
+				expected = append(expected, i)
+			}
+
+			g.Rebalance()
+			checkRB(t, g)
+			checkOrder(t, g, expected)
+
+			g.InsertCtx(ctx, size)
+			g.DeleteCtx(ctx, 0)
+			checkRB(t, g)
+
+			g.Close()
+		}
+	})
+	t.Run("AVL", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.AVL))
+		defer g.Close()
+
+		for i := 0; i < 100; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		g.Rebalance()
+		checkAVL(t, g)
+
+		if h := shapeOf(t, g).height(); h != 7 {
+			t.Fatalf("Expected a height of 7, got %d", h)
+		}
+	})
+	t.Run("Treap", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(1))
+		defer g.Close()
+
+		var expected []int
+		for i := 0; i < 100; i++ {
+			g.InsertCtx(ctx, i)
+			expected = append(expected, i)
+		}
+
+		g.Rebalance()
+
+		if h := shapeOf(t, g).height(); h != 7 {
+			t.Fatalf("Expected a height of 7, got %d", h)
+		}
+
+		// the new priorities have to keep the tree in order as values come
+		// and go
+		for i := 0; i < 100; i += 2 {
+			g.DeleteCtx(ctx, i)
+			g.InsertCtx(ctx, i+100)
+			expected = append(expected, i+100)
+		}
+
+		expected = slices.DeleteFunc(expected, func(v int) bool { return v < 100 && v%2 == 0 })
+		checkOrder(t, g, expected)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for i := 0; i < 10; i++ {
+			for j := 0; j <= i; j++ {
+				g.InsertCtx(ctx, i)
+			}
+		}
+
+		g.Rebalance()
+
+		checkLen(t, g, 55)
+		for i := 0; i < 10; i++ {
+			if c, _ := g.Count(i); c != i+1 {
+				t.Fatalf("Expected a count of %d for %d, got %d", i+1, i, c)
+			}
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for i := 0; i < 100; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// nothing sent while the tree is being rebuilt can go missing
+		var wg sync.WaitGroup
+		for w := 0; w < 4; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 100 + w; i < 300; i += 4 {
+					if ok, err := g.InsertCtx(ctx, i); !ok || err != nil {
+						t.Errorf("Expected a true result from inserting %d, got %v, %v", i, ok, err)
+						return
+					}
+					if ok, _ := g.ContainsCtx(ctx, i-100); !ok {
+						t.Errorf("Expected %d to be found", i-100)
+						return
+					}
+				}
+			}(w)
+		}
+
+		for i := 0; i < 5; i++ {
+			if err := g.Rebalance(); err != nil {
+				t.Fatalf("Expected no error from rebalance, got %v", err)
+			}
+		}
+		wg.Wait()
+
+		var expected []int
+		for i := 0; i < 300; i++ {
+			expected = append(expected, i)
+		}
+		checkOrder(t, g, expected)
+	})
+	t.Run("Map", func(t *testing.T) {
+		m := goroutree.NewMap[int, string]()
+		defer m.Close()
+
+		for i := 0; i < 20; i++ {
+			m.Put(i, strconv.Itoa(i))
+		}
+
+		m.Rebalance()
+
+		for i := 0; i < 20; i++ {
+			if v, ok, _ := m.Get(i); !ok || v != strconv.Itoa(i) {
+				t.Fatalf("Expected %q from get %d, got %q, %v", strconv.Itoa(i), i, v, ok)
+			}
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if err := g.Rebalance(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from rebalance, got %v", err)
+		}
+	})
+}
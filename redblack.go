@@ -0,0 +1,262 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// The red-black mode is a left-leaning red-black tree, as described by
+// Sedgewick. Every node knows its own colour, and its parent knows it (and the
+// colour of its left child) through the subtree it was last sent. That's
+// everything the rules below need to look at, so recolouring and rotating only
+// ever takes messages between a node and its children.
+//
+// Inserts are a plain insert with the tree fixed up on the way back up.
+// Deletes (and extracting the minimum or maximum) also push a red link down
+// ahead of themselves on the way down, so that whatever node comes out at the
+// bottom is red and taking it out doesn't change how many black links there
+// are on any path. Whatever was pushed down gets fixed on the way back up, so
+// this is fine to do even if the value turns out not to be in the tree.
+
+// flipCmd asks a node to flip its colour.
+type flipCmd struct {
+	ack chan subtree
+}
+
+func (c flipCmd) typ() cmdType {
+	return ctFlip
+}
+
+func (n *node[T]) flip(c flipCmd) {
+	n.red = !n.red
+	c.ack <- n.subtree()
+}
+
+// paintRoot keeps the root of the tree black. The manager marks the commands
+// it sends to the root, so root is only ever true there.
+func (n *node[T]) paintRoot(root bool) {
+	if root {
+		n.red = false
+	}
+}
+
+// rebalanceRB fixes up this node on the way back up: no red links leaning
+// right, no two red links in a row and no node with two red children.
+func (n *node[T]) rebalanceRB() {
+	if n.right.red && !n.left.red {
+		n.rotate(false)
+	}
+	if n.left.red && n.left.leftRed {
+		n.rotate(true)
+	}
+	if n.left.red && n.right.red {
+		n.flipColors()
+	}
+}
+
+// flipColors flips the colour of this node and both of its children.
+func (n *node[T]) flipColors() {
+	n.red = !n.red
+
+	for _, child := range []*subtree{&n.left, &n.right} {
+		if child.ch != nil {
+			n.forward(child, flipCmd{ack: n.ackchan})
+		}
+	}
+}
+
+// moveRedLeft makes sure the left child or one of its children is red before
+// heading down the left side to take something out, borrowing from the right
+// side if it has a red link to spare.
+func (n *node[T]) moveRedLeft() {
+	n.flipColors()
+
+	if n.right.leftRed {
+		n.rotateChild(&n.right, true)
+		n.rotate(false)
+		n.flipColors()
+	}
+}
+
+// moveRedRight does the same as moveRedLeft for the right side. It returns true
+// if it had to rotate, which means this node now holds a smaller value than
+// before.
+func (n *node[T]) moveRedRight() bool {
+	n.flipColors()
+
+	if n.left.leftRed {
+		n.rotate(true)
+		n.flipColors()
+		return true
+	}
+
+	return false
+}
+
+// deleteRB is delete for a red-black tree. It returns true if this node has
+// removed itself from the tree.
+func (n *node[T]) deleteRB(c deleteCmd[T]) bool {
+	parentack, root := c.ack, c.root
+	c.ack, c.root = n.ackchan, false
+
+	if root && !n.left.red && !n.right.red {
+		n.red = true
+	}
+
+	res, gone := n.deleteStepRB(c)
+	if gone {
+		// only ever a node without a right child, and a left-leaning tree
+		// can't have a left child there either, so this is a leaf
+		parentack <- n.left
+		c.reschan <- *res
+		return true
+	}
+
+	n.rebalance()
+	n.paintRoot(root)
+
+	parentack <- n.subtree()
+	if res != nil {
+		c.reschan <- *res
+	}
+
+	return false
+}
+
+// deleteStepRB does the work of deleteRB on the way down. It returns the result
+// if this node is the one answering, or nil if a child already has, and whether
+// this node should remove itself.
+func (n *node[T]) deleteStepRB(c deleteCmd[T]) (*Result, bool) {
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		return &Result{Err: err}, false
+	}
+
+	if comparison < 0 {
+		if n.left.ch == nil {
+			return &Result{}, false
+		}
+
+		if !n.left.red && !n.left.leftRed {
+			n.moveRedLeft()
+		}
+
+		n.forward(&n.left, c)
+		return nil, false
+	}
+
+	// a red left link gets turned to the right so there is one to push down
+	// the right side. The value coming up is smaller, so anything that
+	// matched this node is now somewhere to the right.
+	if n.left.red {
+		n.rotate(true)
+		comparison = 1
+	}
+
+	if comparison == 0 && n.count > 1 {
+		n.count--
+		return &Result{Ok: true}, false
+	}
+
+	if comparison == 0 && n.right.ch == nil {
+		return &Result{Ok: true, payload: n.payload}, true
+	}
+
+	if n.right.ch == nil {
+		return &Result{}, false
+	}
+
+	if !n.right.red && !n.right.leftRed && n.moveRedRight() {
+		comparison = 1
+	}
+
+	if comparison == 0 {
+		// same as the unbalanced delete: pull the successor up out of the
+		// right subtree and take its value.
+		reschan := make(chan subtreeMinResponse[T])
+		n.right.ch <- extractMinCmd[T]{reschan: reschan}
+
+		res := <-reschan
+		removed := n.payload
+		n.val = res.val
+		n.payload = res.payload
+		n.count = res.count
+		n.right = res.rest
+
+		return &Result{Ok: true, payload: removed}, false
+	}
+
+	n.forward(&n.right, c)
+	return nil, false
+}
+
+// extractMinRB is extractMin for a red-black tree. It returns true if this node
+// was the minimum (or maximum) and has removed itself from the tree.
+func (n *node[T]) extractMinRB(c extractMinCmd[T]) bool {
+	root := c.root
+	c.root = false
+
+	if root && !n.left.red && !n.right.red {
+		n.red = true
+	}
+
+	if c.max && n.left.red {
+		n.rotate(true)
+	}
+
+	child, other := &n.left, n.right
+	if c.max {
+		child, other = &n.right, n.left
+	}
+
+	var res subtreeMinResponse[T]
+
+	switch {
+	case child.ch != nil:
+		if c.max && !n.right.red && !n.right.leftRed {
+			n.moveRedRight()
+		} else if !c.max && !n.left.red && !n.left.leftRed {
+			n.moveRedLeft()
+		}
+
+		reschan := make(chan subtreeMinResponse[T])
+		child.ch <- extractMinCmd[T]{
+			reschan: reschan,
+			max:     c.max,
+			one:     c.one,
+		}
+
+		res = <-reschan
+		*child = res.rest
+
+	case c.one && n.count > 1:
+		n.count--
+		res = subtreeMinResponse[T]{val: n.val, payload: n.payload, count: 1}
+
+	default:
+		c.reschan <- subtreeMinResponse[T]{
+			val:     n.val,
+			payload: n.payload,
+			count:   n.count,
+			rest:    other,
+		}
+
+		return true
+	}
+
+	n.rebalance()
+	n.paintRoot(root)
+
+	res.rest = n.subtree()
+	c.reschan <- res
+	return false
+}
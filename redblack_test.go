@@ -0,0 +1,163 @@
+package goroutree_test
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// checkRB fails unless the tree is a valid left-leaning red-black tree: a black
+// root, red links only on the left, never two in a row, and the same number of
+// black nodes on every path down.
+func checkRB(t *testing.T, g *goroutree.Goroutree[int]) {
+	t.Helper()
+
+	var blackHeight func(s *shape) int
+	blackHeight = func(s *shape) int {
+		if s == nil {
+			return 0
+		}
+
+		if s.right != nil && s.right.red {
+			t.Fatalf("Expected no red right child under %d", s.val)
+		}
+		if s.red && s.left != nil && s.left.red {
+			t.Fatalf("Expected no two reds in a row at %d", s.val)
+		}
+
+		l, r := blackHeight(s.left), blackHeight(s.right)
+		if l != r {
+			t.Fatalf("Expected the same black height on both sides of %d, got %d and %d", s.val, l, r)
+		}
+
+		if s.red {
+			return l
+		}
+		return l + 1
+	}
+
+	root := shapeOf(t, g)
+	if root != nil && root.red {
+		t.Fatalf("Expected a black root, got %d red", root.val)
+	}
+
+	blackHeight(root)
+}
+
+func TestRedBlack(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Inserts", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for _, i := range []int{1, 2, 3} {
+			g.InsertCtx(ctx, i)
+		}
+
+		if got := printed(t, g); got != " 1\n2\n 3\n" {
+			t.Fatalf("Expected 2 at the root with two black children, got:\n%s", got)
+		}
+
+		g.InsertCtx(ctx, 0)
+
+		if got := printed(t, g); got != "  0 red\n 1\n2\n 3\n" {
+			t.Fatalf("Expected 0 to hang red off of 1, got:\n%s", got)
+		}
+	})
+	t.Run("Depth", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for i := 0; i < 1000; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// a red-black tree of 1000 nodes is at most 2*log2(1001) deep
+		if h := shapeOf(t, g).height(); h > 19 {
+			t.Fatalf("Expected a height of at most 19, got %d", h)
+		}
+		checkRB(t, g)
+		checkLen(t, g, 1000)
+	})
+	t.Run("Random", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		rng := rand.New(rand.NewSource(1))
+		model := map[int]bool{}
+
+		// deleting values that aren't there still pushes red links down the
+		// tree, so those have to leave it valid too
+		for i := 0; i < 2000; i++ {
+			v := rng.Intn(200)
+
+			if rng.Intn(2) == 0 {
+				if ok, _ := g.InsertCtx(ctx, v); ok == model[v] {
+					t.Fatalf("Expected insert %d to return %v", v, !model[v])
+				}
+				model[v] = true
+			} else {
+				if ok, _ := g.DeleteCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected delete %d to return %v", v, model[v])
+				}
+				delete(model, v)
+			}
+
+			checkRB(t, g)
+		}
+
+		var expected []int
+		for v := range model {
+			expected = append(expected, v)
+		}
+		slices.Sort(expected)
+
+		checkOrder(t, g, expected)
+	})
+	t.Run("Pop", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for i := 0; i < 100; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		for i := 0; i < 25; i++ {
+			if v, ok, err := g.PopMin(); v != i || !ok || err != nil {
+				t.Fatalf("Expected %d from pop min, got %d, %v, %v", i, v, ok, err)
+			}
+			if v, ok, err := g.PopMax(); v != 99-i || !ok || err != nil {
+				t.Fatalf("Expected %d from pop max, got %d, %v, %v", 99-i, v, ok, err)
+			}
+			checkRB(t, g)
+		}
+
+		checkLen(t, g, 50)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset(), goroutree.WithBalance(goroutree.RedBlack))
+		defer g.Close()
+
+		for i := 0; i < 40; i++ {
+			g.InsertCtx(ctx, i%20)
+		}
+
+		for i := 0; i < 10; i++ {
+			g.DeleteCtx(ctx, i)
+			g.PopMax()
+			checkRB(t, g)
+		}
+
+		// the pops took both copies of 15 through 19, one at a time
+		checkLen(t, g, 20)
+		for i, expected := range []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 0, 0, 0, 0, 0} {
+			if c, _ := g.Count(i); c != expected {
+				t.Fatalf("Expected a count of %d for %d, got %d", expected, i, c)
+			}
+		}
+	})
+}
@@ -0,0 +1,169 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// Rotations never move a goroutine to a different parent channel. A node's
+// parent only knows it by its cmdchan, and commands that have already been
+// forwarded to it are sitting in that channel, so swapping channels around
+// would need every parent to be told and would strand anything in flight.
+// Instead the node at the top of the rotation trades values with the child
+// coming up: the top node keeps its goroutine and channel but takes over the
+// child's value, and the child's goroutine moves down a level to hold the top
+// node's old value. Only subtrees that hang off the two nodes change hands.
+//
+// A rotation only ever happens on the way back up from a write, while the
+// whole path down to it is held by the write, so nothing else can be routed
+// through the top node in the meantime. Reads that got past it earlier are
+// already below the child (each goroutine does one thing at a time, so the
+// child has passed them on before it sees the pivot) and end up in a subtree
+// that still covers the values they are looking for.
+
+// rotateCmd asks a node to do a single rotation with one of its children, for
+// the first half of a double rotation one level up.
+type rotateCmd struct {
+	toRight bool
+	ack     chan subtree
+}
+
+func (c rotateCmd) typ() cmdType {
+	return ctRotate
+}
+
+// pivotCmd is sent by the top node of a rotation to the child coming up. It
+// carries the top node's value for the child to take, and the top node's other
+// subtree, which ends up under the child.
+type pivotCmd[T any] struct {
+	reschan chan pivotResult[T]
+	toRight bool
+
+	val     T
+	payload any
+	count   int
+	prio    int64
+
+	other subtree
+}
+
+func (c pivotCmd[T]) typ() cmdType {
+	return ctPivot
+}
+
+// pivotResult hands the child's old value back to the top node, along with
+// the subtree that stays on the child's side (far) and the child itself in its
+// new spot on the other side (moved).
+type pivotResult[T any] struct {
+	val     T
+	payload any
+	count   int
+	prio    int64
+
+	far, moved subtree
+}
+
+// rebalance is called whenever one of the node's subtrees has changed on the
+// way back up from a write, after the new subtree has been recorded and before
+// this node reports back to its own parent.
+func (n *node[T]) rebalance() {
+	switch n.cfg.balance {
+	case AVL:
+		n.rebalanceAVL()
+	case RedBlack:
+		n.rebalanceRB()
+	case Treap:
+		n.rebalanceTreap()
+	}
+}
+
+// rotate does a single rotation at this node. With toRight set the left child
+// comes up and this node goes down to the right (a right rotation), otherwise
+// the right child comes up. The colour of the spot at the top stays the same
+// and the node that went down turns red, which is what a red-black tree needs
+// and doesn't matter to anything else.
+func (n *node[T]) rotate(toRight bool) {
+	up, other := &n.right, &n.left
+	if toRight {
+		up, other = &n.left, &n.right
+	}
+
+	reschan := make(chan pivotResult[T])
+	up.ch <- pivotCmd[T]{
+		reschan: reschan,
+		toRight: toRight,
+		val:     n.val,
+		payload: n.payload,
+		count:   n.count,
+		prio:    n.prio,
+		other:   *other,
+	}
+
+	res := <-reschan
+	n.val = res.val
+	n.payload = res.payload
+	n.count = res.count
+	n.prio = res.prio
+	*up = res.far
+	*other = res.moved
+}
+
+// rotateAt does a rotation on behalf of the parent and tells it what this spot
+// looks like afterwards.
+func (n *node[T]) rotateAt(c rotateCmd) {
+	n.rotate(c.toRight)
+	c.ack <- n.subtree()
+}
+
+// rotateChild has one of the children do a rotation of its own and records
+// what that spot looks like afterwards.
+func (n *node[T]) rotateChild(child *subtree, toRight bool) {
+	n.forward(child, rotateCmd{toRight: toRight, ack: n.ackchan})
+}
+
+// pivot is the child's half of a rotation. The subtree on the far side stays
+// where it is, relative to the top, and goes back up; the near one stays with
+// this goroutine, which picks up the parent's value and other subtree.
+func (n *node[T]) pivot(c pivotCmd[T]) {
+	res := pivotResult[T]{
+		val:     n.val,
+		payload: n.payload,
+		count:   n.count,
+		prio:    n.prio,
+	}
+
+	n.val = c.val
+	n.payload = c.payload
+	n.count = c.count
+	n.prio = c.prio
+	n.red = true
+
+	if c.toRight {
+		// this was the left child, and moves to the right
+		res.far = n.left
+		n.left = n.right
+		n.right = c.other
+	} else {
+		res.far = n.right
+		n.right = n.left
+		n.left = c.other
+	}
+
+	// in a Treap the value that came down might not belong this high up any
+	// more, so it keeps sinking before the parent hears back
+	if n.cfg.balance == Treap {
+		n.rebalanceTreap()
+	}
+
+	res.moved = n.subtree()
+	c.reschan <- res
+}
@@ -0,0 +1,221 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+// The set operations below work from a snapshot of each tree, taken with a
+// walk the same way All does, and then go through the two snapshots together
+// in order. The trees are copied one after the other rather than walked at
+// the same time: a walk holds its tree still until it's done, so two callers
+// walking the same two trees in opposite orders would each be stuck waiting on
+// the other. Each snapshot is still a consistent copy of its tree, so the
+// answer is always the operation applied to the two trees as they were at
+// some moment while it ran, no matter what else is going on.
+//
+// Both trees have to order their values the same way. The values are
+// compared with the receiver's ordering.
+//
+// In a Multiset tree each value is counted: a union has as many of each value
+// as whichever tree has more, an intersection as many as whichever has fewer,
+// and a difference has what's left after taking away the other tree's count.
+//
+// The two trees don't need the same options. The new tree from Union,
+// Intersection and Difference always has the receiver's options, so if the
+// receiver isn't a Multiset it holds each value at most once, whatever the
+// counts in other are. The in-place operations only ever insert into or delete
+// from the receiver, so it keeps its own rules there too.
+
+// Union returns a new tree holding every value that's in either g or other.
+// It has the same options as g and is built balanced, the same way FromSorted
+// builds it.
+func (g *Goroutree[T]) Union(other *Goroutree[T]) (*Goroutree[T], error) {
+	return g.combine(other, func(na, nb int) int {
+		return max(na, nb)
+	})
+}
+
+// Intersection returns a new tree holding every value that's in both g and
+// other. See Union.
+func (g *Goroutree[T]) Intersection(other *Goroutree[T]) (*Goroutree[T], error) {
+	return g.combine(other, func(na, nb int) int {
+		return min(na, nb)
+	})
+}
+
+// Difference returns a new tree holding every value that's in g but not in
+// other. See Union.
+func (g *Goroutree[T]) Difference(other *Goroutree[T]) (*Goroutree[T], error) {
+	return g.combine(other, func(na, nb int) int {
+		return na - nb
+	})
+}
+
+// AddAll inserts every value in other into g, all in one InsertBatch. In a
+// Multiset tree every occurrence is inserted, so the counts add up.
+func (g *Goroutree[T]) AddAll(other *Goroutree[T]) error {
+	b, err := other.entries()
+	if err != nil {
+		return err
+	}
+
+	_, err = g.InsertBatch(occurrences(b))
+	return err
+}
+
+// RemoveAll deletes every value in other from g, all in one DeleteBatch. In a
+// Multiset tree every occurrence is deleted, the same as for Difference.
+func (g *Goroutree[T]) RemoveAll(other *Goroutree[T]) error {
+	b, err := other.entries()
+	if err != nil {
+		return err
+	}
+
+	_, err = g.DeleteBatch(occurrences(b))
+	return err
+}
+
+// RetainAll deletes every value from g that isn't in other, all in one
+// DeleteBatch. In a Multiset tree each value is left with no more occurrences
+// than other has, the same as for Intersection. Anything inserted into g after
+// it was copied is left alone.
+func (g *Goroutree[T]) RetainAll(other *Goroutree[T]) error {
+	a, b, err := g.both(other)
+	if err != nil {
+		return err
+	}
+
+	var deletes []T
+	err = mergeEntries(a, b, g.cfg.compare, func(val T, na, nb int) {
+		for i := nb; i < na; i++ {
+			deletes = append(deletes, val)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = g.DeleteBatch(deletes)
+	return err
+}
+
+// combine builds a new tree where each value appears as many times as count
+// says, given how many times it's in g and other.
+func (g *Goroutree[T]) combine(other *Goroutree[T], count func(na, nb int) int) (*Goroutree[T], error) {
+	a, b, err := g.both(other)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []entry[T]
+	err = mergeEntries(a, b, g.cfg.compare, func(val T, na, nb int) {
+		c := count(na, nb)
+		if !g.cfg.multiset {
+			c = min(c, 1)
+		}
+
+		if c > 0 {
+			entries = append(entries, entry[T]{val: val, count: c})
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := g.cfg.clone()
+	return start(cfg, build(entries, cfg)), nil
+}
+
+// both copies g and then other. A tree combined with itself is only copied
+// once, since the two walks would be the same anyway.
+func (g *Goroutree[T]) both(other *Goroutree[T]) ([]entry[T], []entry[T], error) {
+	a, err := g.entries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if other == g {
+		return a, a, nil
+	}
+
+	b, err := other.entries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return a, b, nil
+}
+
+// entries copies every value in the tree, in order, with its count.
+func (g *Goroutree[T]) entries() ([]entry[T], error) {
+	w, err := g.startWalk(context.Background(), nil, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []entry[T]
+	err = w.each(func(val T, count int) bool {
+		out = append(out, entry[T]{val: val, count: count})
+		return true
+	})
+
+	return out, err
+}
+
+// mergeEntries goes through two sorted lists of entries together and calls fn
+// with every value in either one, in order, and how many times each has it.
+func mergeEntries[T any](a, b []entry[T], compare func(a, b T) (int, error), fn func(val T, na, nb int)) error {
+	for len(a) > 0 || len(b) > 0 {
+		var comparison int
+		switch {
+		case len(a) == 0:
+			comparison = 1
+		case len(b) == 0:
+			comparison = -1
+		default:
+			var err error
+			comparison, err = compare(a[0].val, b[0].val)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case comparison < 0:
+			fn(a[0].val, a[0].count, 0)
+			a = a[1:]
+		case comparison > 0:
+			fn(b[0].val, 0, b[0].count)
+			b = b[1:]
+		default:
+			fn(a[0].val, a[0].count, b[0].count)
+			a, b = a[1:], b[1:]
+		}
+	}
+
+	return nil
+}
+
+// occurrences lists every value in entries as many times as it's counted.
+func occurrences[T any](entries []entry[T]) []T {
+	var vals []T
+	for _, e := range entries {
+		for i := 0; i < e.count; i++ {
+			vals = append(vals, e.val)
+		}
+	}
+
+	return vals
+}
@@ -0,0 +1,266 @@
+package goroutree_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestSetOps(t *testing.T) {
+	ctx := context.Background()
+
+	load := func(vals []int, opts ...goroutree.Option) *goroutree.Goroutree[int] {
+		g := goroutree.NewOrdered[int](opts...)
+		for _, v := range vals {
+			g.InsertCtx(ctx, v)
+		}
+		return g
+	}
+
+	t.Run("Combine", func(t *testing.T) {
+		a := load([]int{1, 3, 5, 7, 9, 11})
+		defer a.Close()
+		b := load([]int{3, 4, 5, 6, 7})
+		defer b.Close()
+
+		for _, tc := range []struct {
+			name     string
+			op       func(*goroutree.Goroutree[int]) (*goroutree.Goroutree[int], error)
+			expected []int
+		}{
+			{"Union", a.Union, []int{1, 3, 4, 5, 6, 7, 9, 11}},
+			{"Intersection", a.Intersection, []int{3, 5, 7}},
+			{"Difference", a.Difference, []int{1, 9, 11}},
+		} {
+			res, err := tc.op(b)
+			if err != nil {
+				t.Fatalf("Expected no error from %s, got %v", tc.name, err)
+			}
+
+			checkOrder(t, res, tc.expected)
+
+			// the result is a tree of its own
+			res.InsertCtx(ctx, 100)
+			res.Close()
+		}
+
+		checkOrder(t, a, []int{1, 3, 5, 7, 9, 11})
+		checkOrder(t, b, []int{3, 4, 5, 6, 7})
+	})
+	t.Run("Balanced", func(t *testing.T) {
+		a := load([]int{0, 1, 2, 3, 4, 5, 6, 7})
+		defer a.Close()
+		b := load([]int{8, 9, 10, 11, 12, 13, 14})
+		defer b.Close()
+
+		u, _ := a.Union(b)
+		defer u.Close()
+
+		ref := goroutree.NewOrdered[int]()
+		defer ref.Close()
+
+		for _, i := range balancedOrder {
+			ref.InsertCtx(ctx, i)
+		}
+
+		if got, expected := printed(t, u), printed(t, ref); got != expected {
+			t.Fatalf("Expected a perfectly balanced tree:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Options", func(t *testing.T) {
+		a := load([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, goroutree.WithBalance(goroutree.RedBlack))
+		defer a.Close()
+		b := load([]int{20, 21, 22})
+		defer b.Close()
+
+		u, _ := a.Union(b)
+		defer u.Close()
+
+		checkRB(t, u)
+		u.InsertCtx(ctx, 30)
+		checkRB(t, u)
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		a := load([]int{1, 1, 1, 2, 3, 3}, goroutree.Multiset())
+		defer a.Close()
+		b := load([]int{1, 2, 2, 3, 3, 3, 4}, goroutree.Multiset())
+		defer b.Close()
+
+		u, _ := a.Union(b)
+		defer u.Close()
+		checkOrder(t, u, []int{1, 1, 1, 2, 2, 3, 3, 3, 4})
+
+		i, _ := a.Intersection(b)
+		defer i.Close()
+		checkOrder(t, i, []int{1, 2, 3, 3})
+
+		d, _ := a.Difference(b)
+		defer d.Close()
+		checkOrder(t, d, []int{1, 1})
+	})
+	t.Run("MixedOptions", func(t *testing.T) {
+		a := load([]int{1, 2, 3})
+		defer a.Close()
+		b := load([]int{1, 1, 1, 2, 2, 4}, goroutree.Multiset())
+		defer b.Close()
+
+		// a isn't a Multiset, so neither is anything made from it
+		for _, tc := range []struct {
+			name  string
+			op    func(*goroutree.Goroutree[int]) (*goroutree.Goroutree[int], error)
+			len   int
+			count int
+		}{
+			{"Union", a.Union, 4, 1},
+			{"Intersection", a.Intersection, 2, 1},
+			{"Difference", a.Difference, 1, 0},
+		} {
+			res, err := tc.op(b)
+			if err != nil {
+				t.Fatalf("Expected no error from %s, got %v", tc.name, err)
+			}
+
+			checkLen(t, res, tc.len)
+			if c, _ := res.Count(1); c != tc.count {
+				t.Fatalf("Expected a count of %d for 1 from %s, got %d", tc.count, tc.name, c)
+			}
+
+			res.Close()
+		}
+
+		// and the other way round, the counts are kept
+		u, _ := b.Union(a)
+		defer u.Close()
+
+		checkLen(t, u, 7)
+		if c, _ := u.Count(1); c != 3 {
+			t.Fatalf("Expected a count of 3 for 1, got %d", c)
+		}
+	})
+	t.Run("Self", func(t *testing.T) {
+		a := load([]int{2, 1, 3})
+		defer a.Close()
+
+		u, err := a.Union(a)
+		if err != nil {
+			t.Fatalf("Expected no error from union, got %v", err)
+		}
+		defer u.Close()
+		checkOrder(t, u, []int{1, 2, 3})
+
+		if err := a.RemoveAll(a); err != nil {
+			t.Fatalf("Expected no error from remove all, got %v", err)
+		}
+		checkLen(t, a, 0)
+	})
+	t.Run("InPlace", func(t *testing.T) {
+		a := load([]int{1, 3, 5, 7})
+		defer a.Close()
+		b := load([]int{3, 4, 5})
+		defer b.Close()
+		c := load([]int{4, 5, 7, 8})
+		defer c.Close()
+
+		if err := a.AddAll(b); err != nil {
+			t.Fatalf("Expected no error from add all, got %v", err)
+		}
+		checkOrder(t, a, []int{1, 3, 4, 5, 7})
+
+		if err := a.RetainAll(c); err != nil {
+			t.Fatalf("Expected no error from retain all, got %v", err)
+		}
+		checkOrder(t, a, []int{4, 5, 7})
+
+		if err := a.RemoveAll(b); err != nil {
+			t.Fatalf("Expected no error from remove all, got %v", err)
+		}
+		checkOrder(t, a, []int{7})
+	})
+	t.Run("InPlaceMultiset", func(t *testing.T) {
+		a := load([]int{1, 1, 2}, goroutree.Multiset())
+		defer a.Close()
+		b := load([]int{1, 2, 2, 2}, goroutree.Multiset())
+		defer b.Close()
+
+		a.AddAll(b)
+		checkOrder(t, a, []int{1, 1, 1, 2, 2, 2, 2})
+
+		a.RemoveAll(b)
+		checkOrder(t, a, []int{1, 1, 2})
+
+		a.RetainAll(b)
+		checkOrder(t, a, []int{1, 2})
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		a := load(nil)
+		defer a.Close()
+		b := load(nil)
+		defer b.Close()
+
+		// walking two trees in opposite orders at the same time can't get
+		// stuck, and every answer is made of values that were really there
+		var wg sync.WaitGroup
+		for w, pair := range [][2]*goroutree.Goroutree[int]{{a, b}, {b, a}, {a, b}, {b, a}} {
+			wg.Add(1)
+			go func(w int, x, y *goroutree.Goroutree[int]) {
+				defer wg.Done()
+
+				for i := 0; i < 50; i++ {
+					x.InsertCtx(ctx, i*4+w)
+
+					u, err := x.Union(y)
+					if err != nil {
+						t.Errorf("Expected no error from union, got %v", err)
+						return
+					}
+					if vals := slices.Collect(u.All()); !slices.IsSorted(vals) || len(vals) == 0 {
+						t.Errorf("Expected a sorted union, got %v", vals)
+						return
+					}
+					u.Close()
+
+					if err := x.AddAll(y); err != nil {
+						t.Errorf("Expected no error from add all, got %v", err)
+						return
+					}
+				}
+			}(w, pair[0], pair[1])
+		}
+		wg.Wait()
+
+		// everything ended up in both, one way or another
+		a.AddAll(b)
+		b.AddAll(a)
+		checkLen(t, a, 200)
+		checkLen(t, b, 200)
+	})
+	t.Run("Closed", func(t *testing.T) {
+		a := load([]int{1})
+		defer a.Close()
+		b := load([]int{2})
+		b.Close()
+
+		if _, err := a.Union(b); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from union, got %v", err)
+		}
+		if err := a.AddAll(b); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from add all, got %v", err)
+		}
+	})
+	t.Run("Comparer", func(t *testing.T) {
+		a := goroutree.New()
+		defer a.Close()
+		b := goroutree.New()
+		defer b.Close()
+
+		a.InsertCtx(ctx, goroutree.Int(1))
+		b.InsertCtx(ctx, Str("a"))
+
+		if _, err := a.Union(b); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from union, got %v", err)
+		}
+	})
+}
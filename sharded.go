@@ -0,0 +1,347 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"iter"
+	"slices"
+)
+
+// Sharded is a set split into several Goroutrees by ranges of values. Every
+// write to a Goroutree goes through its manager and root node one at a time, so
+// those two goroutines limit how fast the whole tree can go no matter how many
+// nodes there are under them. Sharded gives each range of values its own
+// manager and root, and sends each command straight to the one that owns the
+// value, so commands for different ranges never wait on each other.
+//
+// Anything that only touches one value behaves exactly as it does on a single
+// Goroutree. Anything that looks at the whole set (Len, Min, Max, PopMin,
+// PopMax and All) goes through the shards one after another, so it sees each
+// shard as it was when it got there rather than the whole set at one moment.
+type Sharded[T any] struct {
+	compare func(a, b T) (int, error)
+
+	// shards[i] holds the values from splits[i-1] up to but not including
+	// splits[i]. The first and last shards have no bound on their outer side.
+	splits []T
+	shards []*Goroutree[T]
+}
+
+// ErrDuplicateSplit is returned when making a Sharded set with the same split
+// point given more than once.
+var ErrDuplicateSplit = errors.New("goroutree: split points must all be different")
+
+// NewSharded creates a new empty Sharded set of Comparer values, ordered by
+// their Compare method, with a shard for each range between the split points.
+// The options are applied to every shard. The split points can be given in any
+// order, but they all have to be different and comparable to each other: if
+// two of them can't be compared, the error from Compare is returned, and if
+// two are the same ErrDuplicateSplit is.
+func NewSharded(splits []Comparer, opts ...Option) (*Sharded[Comparer], error) {
+	return newSharded(splits, func(a, b Comparer) (int, error) {
+		return a.Compare(b)
+	}, opts)
+}
+
+// NewShardedOrdered creates a new empty Sharded set of values that can be
+// ordered with the < operator, with a shard for each range between the split
+// points. See NewSharded.
+func NewShardedOrdered[T cmp.Ordered](splits []T, opts ...Option) (*Sharded[T], error) {
+	return NewShardedFunc(splits, cmp.Compare[T], opts...)
+}
+
+// NewShardedFunc creates a new empty Sharded set ordered by the given function,
+// which follows the same rules as the one passed to NewFunc, with a shard for
+// each range between the split points. See NewSharded.
+func NewShardedFunc[T any](splits []T, compare func(a, b T) int, opts ...Option) (*Sharded[T], error) {
+	return newSharded(splits, func(a, b T) (int, error) {
+		return compare(a, b), nil
+	}, opts)
+}
+
+func newSharded[T any](splits []T, compare func(a, b T) (int, error), opts []Option) (*Sharded[T], error) {
+	var err error
+	splits = slices.Clone(splits)
+	slices.SortFunc(splits, func(a, b T) int {
+		c, cerr := compare(a, b)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// sorted, any repeats are next to each other
+	for i := 1; i < len(splits); i++ {
+		if c, _ := compare(splits[i-1], splits[i]); c == 0 {
+			return nil, ErrDuplicateSplit
+		}
+	}
+
+	s := &Sharded[T]{
+		compare: compare,
+		splits:  splits,
+		shards:  make([]*Goroutree[T], len(splits)+1),
+	}
+	for i := range s.shards {
+		s.shards[i] = newTree(compare, opts)
+	}
+
+	return s, nil
+}
+
+// SampleSplits picks split points for n shards out of a sample of the values
+// that are expected to go into the set, so that each shard gets about the same
+// share of them. Where the sample repeats a value enough that two split points
+// would land on it, the later one moves up to the next value in the sample
+// instead. It returns fewer than n-1 split points if the sample doesn't have
+// enough distinct values to go around.
+func SampleSplits[T any](sample []T, n int, compare func(a, b T) int) []T {
+	if len(sample) == 0 || n < 2 {
+		return nil
+	}
+
+	sorted := slices.Clone(sample)
+	slices.SortFunc(sorted, compare)
+
+	// every split point has to be above the one before it, and the first one
+	// above the smallest value so the first shard gets some of the sample
+	floor := sorted[0]
+
+	var splits []T
+	for i := 1; i < n; i++ {
+		j := i * len(sorted) / n
+		for j < len(sorted) && compare(sorted[j], floor) <= 0 {
+			j++
+		}
+		if j == len(sorted) {
+			break
+		}
+
+		floor = sorted[j]
+		splits = append(splits, floor)
+	}
+
+	return splits
+}
+
+// shard finds the shard that owns val. It returns an error if val can't be
+// compared against the split points.
+func (s *Sharded[T]) shard(val T) (*Goroutree[T], error) {
+	var err error
+	i, found := slices.BinarySearchFunc(s.splits, val, func(split, val T) int {
+		c, cerr := s.compare(split, val)
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+		return c
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// a split point belongs to the shard above it
+	if found {
+		i++
+	}
+
+	return s.shards[i], nil
+}
+
+// Shards returns the number of shards the set is split into.
+func (s *Sharded[T]) Shards() int {
+	return len(s.shards)
+}
+
+// Insert adds a value to the set. See Goroutree.Insert. If the value can't be
+// compared against the split points, the error is returned and nothing is
+// sent on the channel.
+func (s *Sharded[T]) Insert(reschan chan Result, val T) error {
+	g, err := s.shard(val)
+	if err != nil {
+		return err
+	}
+
+	return g.Insert(reschan, val)
+}
+
+// Contains checks whether a value is in the set. See Goroutree.Contains. If the
+// value can't be compared against the split points, the error is returned and
+// nothing is sent on the channel.
+func (s *Sharded[T]) Contains(reschan chan Result, val T) error {
+	g, err := s.shard(val)
+	if err != nil {
+		return err
+	}
+
+	return g.Contains(reschan, val)
+}
+
+// Delete takes a value out of the set. See Goroutree.Delete. If the value can't
+// be compared against the split points, the error is returned and nothing is
+// sent on the channel.
+func (s *Sharded[T]) Delete(reschan chan Result, val T) error {
+	g, err := s.shard(val)
+	if err != nil {
+		return err
+	}
+
+	return g.Delete(reschan, val)
+}
+
+// InsertCtx adds a value to the set and waits for the answer. See
+// Goroutree.InsertCtx.
+func (s *Sharded[T]) InsertCtx(ctx context.Context, val T) (bool, error) {
+	g, err := s.shard(val)
+	if err != nil {
+		return false, err
+	}
+
+	return g.InsertCtx(ctx, val)
+}
+
+// ContainsCtx checks whether a value is in the set and waits for the answer.
+// See Goroutree.ContainsCtx.
+func (s *Sharded[T]) ContainsCtx(ctx context.Context, val T) (bool, error) {
+	g, err := s.shard(val)
+	if err != nil {
+		return false, err
+	}
+
+	return g.ContainsCtx(ctx, val)
+}
+
+// DeleteCtx takes a value out of the set and waits for the answer. See
+// Goroutree.DeleteCtx.
+func (s *Sharded[T]) DeleteCtx(ctx context.Context, val T) (bool, error) {
+	g, err := s.shard(val)
+	if err != nil {
+		return false, err
+	}
+
+	return g.DeleteCtx(ctx, val)
+}
+
+// Count returns how many times val is in the set. See Goroutree.Count.
+func (s *Sharded[T]) Count(val T) (int, error) {
+	g, err := s.shard(val)
+	if err != nil {
+		return 0, err
+	}
+
+	return g.Count(val)
+}
+
+// Len returns the number of values in the set, adding up the shards one at a
+// time.
+func (s *Sharded[T]) Len() (int, error) {
+	total := 0
+	for _, g := range s.shards {
+		l, err := g.Len()
+		if err != nil {
+			return 0, err
+		}
+		total += l
+	}
+
+	return total, nil
+}
+
+// Min returns the smallest value in the set, from the first shard that isn't
+// empty. The bool is false if the set is empty.
+func (s *Sharded[T]) Min() (T, bool, error) {
+	return s.first((*Goroutree[T]).Min, false)
+}
+
+// Max returns the largest value in the set, from the last shard that isn't
+// empty. The bool is false if the set is empty.
+func (s *Sharded[T]) Max() (T, bool, error) {
+	return s.first((*Goroutree[T]).Max, true)
+}
+
+// PopMin removes the smallest value from the set and returns it. The bool is
+// false if the set is empty. A value inserted into an earlier shard while
+// this is going on might be missed, in which case the one returned is the
+// smallest of those that were there already.
+func (s *Sharded[T]) PopMin() (T, bool, error) {
+	return s.first((*Goroutree[T]).PopMin, false)
+}
+
+// PopMax removes the largest value from the set and returns it, the same way
+// PopMin does for the smallest.
+func (s *Sharded[T]) PopMax() (T, bool, error) {
+	return s.first((*Goroutree[T]).PopMax, true)
+}
+
+// first asks each shard in turn, starting from the lowest or from the highest,
+// until one of them has an answer.
+func (s *Sharded[T]) first(fn func(*Goroutree[T]) (T, bool, error), backward bool) (T, bool, error) {
+	for i := range s.shards {
+		if backward {
+			i = len(s.shards) - 1 - i
+		}
+
+		val, ok, err := fn(s.shards[i])
+		if ok || err != nil {
+			return val, ok, err
+		}
+	}
+
+	var zero T
+	return zero, false, nil
+}
+
+// All returns an iterator over the values in the set in increasing order,
+// walking each shard in turn. See Goroutree.All.
+func (s *Sharded[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, g := range s.shards {
+			for val := range g.All() {
+				if !yield(val) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Rebalance rebuilds every shard as a balanced tree. See Goroutree.Rebalance.
+func (s *Sharded[T]) Rebalance() error {
+	for _, g := range s.shards {
+		if err := g.Rebalance(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close shuts down every shard. Any operation after Close returns ErrClosed,
+// including a second call to Close.
+func (s *Sharded[T]) Close() error {
+	var err error
+	for _, g := range s.shards {
+		if cerr := g.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,245 @@
+package goroutree_test
+
+import (
+	"cmp"
+	"context"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestSharded(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Routing", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered([]int{20, 10})
+		defer s.Close()
+
+		if s.Shards() != 3 {
+			t.Fatalf("Expected 3 shards, got %d", s.Shards())
+		}
+
+		// the split points themselves go to the shard above them
+		for _, v := range []int{25, 10, 9, 20, 19, 0} {
+			if ok, err := s.InsertCtx(ctx, v); !ok || err != nil {
+				t.Fatalf("Expected a true result from inserting %d, got %v, %v", v, ok, err)
+			}
+		}
+		if ok, _ := s.InsertCtx(ctx, 10); ok {
+			t.Fatalf("Expected a false result from inserting duplicate")
+		}
+
+		if got := slices.Collect(s.All()); !slices.Equal(got, []int{0, 9, 10, 19, 20, 25}) {
+			t.Fatalf("Expected all values in order, got %v", got)
+		}
+
+		if ok, _ := s.ContainsCtx(ctx, 20); !ok {
+			t.Fatalf("Expected a true result from contains")
+		}
+		if ok, _ := s.DeleteCtx(ctx, 20); !ok {
+			t.Fatalf("Expected a true result from delete")
+		}
+		if ok, _ := s.ContainsCtx(ctx, 20); ok {
+			t.Fatalf("Expected a false result from contains after delete")
+		}
+		checkLen(t, s, 5)
+	})
+	t.Run("NoSplits", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered[int](nil)
+		defer s.Close()
+
+		s.InsertCtx(ctx, 3)
+		if s.Shards() != 1 {
+			t.Fatalf("Expected 1 shard, got %d", s.Shards())
+		}
+		checkLen(t, s, 1)
+	})
+	t.Run("MinMax", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered([]int{10, 20, 30})
+		defer s.Close()
+
+		if _, ok, err := s.Min(); ok || err != nil {
+			t.Fatalf("Expected nothing from an empty set, got %v, %v", ok, err)
+		}
+
+		for _, v := range []int{15, 25, 12, 28} {
+			s.InsertCtx(ctx, v)
+		}
+
+		if v, ok, _ := s.Min(); v != 12 || !ok {
+			t.Fatalf("Expected 12 from min, got %d, %v", v, ok)
+		}
+		if v, ok, _ := s.Max(); v != 28 || !ok {
+			t.Fatalf("Expected 28 from max, got %d, %v", v, ok)
+		}
+
+		var popped []int
+		for {
+			v, ok, err := s.PopMin()
+			if err != nil {
+				t.Fatalf("Expected no error from pop, got %v", err)
+			}
+			if !ok {
+				break
+			}
+			popped = append(popped, v)
+		}
+		if !slices.Equal(popped, []int{12, 15, 25, 28}) {
+			t.Fatalf("Expected values popped in order, got %v", popped)
+		}
+	})
+	t.Run("SampleSplits", func(t *testing.T) {
+		var sample []int
+		for i := 99; i >= 0; i-- {
+			sample = append(sample, i)
+		}
+
+		if splits := goroutree.SampleSplits(sample, 4, cmp.Compare[int]); !slices.Equal(splits, []int{25, 50, 75}) {
+			t.Fatalf("Expected even splits, got %v", splits)
+		}
+
+		// there's nowhere to split a sample with one value in it
+		if splits := goroutree.SampleSplits([]int{5, 5, 5, 5}, 4, cmp.Compare[int]); len(splits) != 0 {
+			t.Fatalf("Expected no splits, got %v", splits)
+		}
+		if splits := goroutree.SampleSplits([]int{1, 1, 1, 2}, 4, cmp.Compare[int]); !slices.Equal(splits, []int{2}) {
+			t.Fatalf("Expected one split, got %v", splits)
+		}
+
+		// a repeated value moves the split point on to the next one
+		if splits := goroutree.SampleSplits([]int{1, 1, 1, 1, 2}, 3, cmp.Compare[int]); !slices.Equal(splits, []int{2}) {
+			t.Fatalf("Expected one split, got %v", splits)
+		}
+		if splits := goroutree.SampleSplits([]int{1, 2, 2, 2, 2, 3, 4, 5}, 4, cmp.Compare[int]); !slices.Equal(splits, []int{2, 3, 4}) {
+			t.Fatalf("Expected three splits, got %v", splits)
+		}
+	})
+	t.Run("BadSplits", func(t *testing.T) {
+		if _, err := goroutree.NewShardedOrdered([]int{10, 20, 10}); err != goroutree.ErrDuplicateSplit {
+			t.Fatalf("Expected ErrDuplicateSplit, got %v", err)
+		}
+
+		// split points that can't be compared can't be put in order either
+		if _, err := goroutree.NewSharded([]goroutree.Comparer{goroutree.Int(10), Str("m")}); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable, got %v", err)
+		}
+	})
+	t.Run("Random", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+
+		var sample []int
+		for i := 0; i < 100; i++ {
+			sample = append(sample, rng.Intn(500))
+		}
+
+		s, _ := goroutree.NewShardedOrdered(goroutree.SampleSplits(sample, 8, cmp.Compare[int]), goroutree.WithBalance(goroutree.AVL))
+		defer s.Close()
+
+		model := map[int]bool{}
+		for i := 0; i < 2000; i++ {
+			v := rng.Intn(500)
+
+			if rng.Intn(2) == 0 {
+				if ok, _ := s.InsertCtx(ctx, v); ok == model[v] {
+					t.Fatalf("Expected insert %d to return %v", v, !model[v])
+				}
+				model[v] = true
+			} else {
+				if ok, _ := s.DeleteCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected delete %d to return %v", v, model[v])
+				}
+				delete(model, v)
+			}
+		}
+
+		var expected []int
+		for v := range model {
+			expected = append(expected, v)
+		}
+		slices.Sort(expected)
+
+		if got := slices.Collect(s.All()); !slices.Equal(got, expected) {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+		checkLen(t, s, len(expected))
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered([]int{5}, goroutree.Multiset())
+		defer s.Close()
+
+		for _, v := range []int{3, 7, 7, 3, 7} {
+			s.InsertCtx(ctx, v)
+		}
+
+		if c, _ := s.Count(7); c != 3 {
+			t.Fatalf("Expected a count of 3, got %d", c)
+		}
+		checkLen(t, s, 5)
+	})
+	t.Run("Comparer", func(t *testing.T) {
+		s, _ := goroutree.NewSharded([]goroutree.Comparer{goroutree.Int(10)})
+		defer s.Close()
+
+		if ok, err := s.InsertCtx(ctx, goroutree.Int(4)); !ok || err != nil {
+			t.Fatalf("Expected a true result from inserting, got %v, %v", ok, err)
+		}
+
+		// routing has to compare against the split points, and fails first
+		if _, err := s.InsertCtx(ctx, Str("m")); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from inserting, got %v", err)
+		}
+		if err := s.Insert(make(chan goroutree.Result, 1), Str("m")); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable from inserting, got %v", err)
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered([]int{200, 400, 600})
+		defer s.Close()
+
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < 100; i++ {
+					v := i*8 + w
+					if ok, err := s.InsertCtx(ctx, v); !ok || err != nil {
+						t.Errorf("Expected a true result from inserting %d, got %v, %v", v, ok, err)
+						return
+					}
+					if ok, _ := s.ContainsCtx(ctx, v); !ok {
+						t.Errorf("Expected %d to be found right after inserting it", v)
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		checkLen(t, s, 800)
+		if err := s.Rebalance(); err != nil {
+			t.Fatalf("Expected no error from rebalance, got %v", err)
+		}
+		checkLen(t, s, 800)
+	})
+	t.Run("Closed", func(t *testing.T) {
+		s, _ := goroutree.NewShardedOrdered([]int{10})
+		if err := s.Close(); err != nil {
+			t.Fatalf("Expected no error from close, got %v", err)
+		}
+
+		if _, err := s.InsertCtx(ctx, 20); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from inserting, got %v", err)
+		}
+		if _, err := s.Len(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from len, got %v", err)
+		}
+		if err := s.Close(); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from closing again, got %v", err)
+		}
+	})
+}
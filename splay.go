@@ -0,0 +1,117 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// A Splay tree is splayed bottom up, on the way back from a command. Each ack
+// says where the value the command touched is now, relative to the spot it
+// is for: right there, one of its children, or nowhere. A node that hears its
+// child holds the value just passes that on, so the node above can take both
+// levels at once with a zig-zig or zig-zag. The root finishes off with a
+// single rotation if the value is left one level short.
+
+// splayPos is where a touched value is, relative to a subtree.
+type splayPos int
+
+const (
+	splayNone splayPos = iota
+	splayTop
+	splayLeft
+	splayRight
+)
+
+// touched is the subtree to ack with when this node holds the value that was
+// touched.
+func (n *node[T]) touched() subtree {
+	st := n.subtree()
+	st.splay = splayTop
+	return st
+}
+
+// report works out what to ack with after a command has come back up from one
+// of the children. In a Splay tree this is where the touched value gets moved
+// up. root is set if this is the root of the whole tree.
+func (n *node[T]) report(child *subtree, root bool) subtree {
+	if n.cfg.balance != Splay {
+		return n.subtree()
+	}
+
+	left := child == &n.left
+
+	switch child.splay {
+	case splayNone:
+		return n.subtree()
+
+	case splayTop:
+		if !root {
+			// leave it for the node above
+			st := n.subtree()
+			st.splay = splayRight
+			if left {
+				st.splay = splayLeft
+			}
+			return st
+		}
+
+		// zig
+		n.rotate(left)
+
+	case splayLeft, splayRight:
+		if (child.splay == splayLeft) == left {
+			// zig-zig: the child comes up, then the value under it
+			n.rotate(left)
+			n.rotate(left)
+		} else {
+			// zig-zag: the value comes up to the child's spot, then here
+			n.rotateChild(child, !left)
+			n.rotate(left)
+		}
+	}
+
+	return n.touched()
+}
+
+// containsSplay is contains for a Splay tree, which holds on to the path down
+// and moves the value up on the way back if it's found.
+func (n *node[T]) containsSplay(c containsCmd[T]) {
+	comparison, err := n.cfg.compare(c.val, n.val)
+	if err != nil {
+		c.ack <- n.subtree()
+		c.reschan <- Result{Err: err}
+		return
+	}
+
+	if comparison == 0 {
+		c.ack <- n.touched()
+		c.reschan <- Result{Ok: true, payload: n.payload, count: n.count}
+		return
+	}
+
+	child := &n.left
+	if comparison > 0 {
+		child = &n.right
+	}
+
+	if child.ch == nil {
+		c.ack <- n.subtree()
+		c.reschan <- Result{}
+		return
+	}
+
+	parentack, root := c.ack, c.root
+	c.ack, c.root = n.ackchan, false
+	n.forward(child, c)
+
+	parentack <- n.report(child, root)
+}
@@ -0,0 +1,227 @@
+package goroutree_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// splayRef is a plain bottom-up splay tree to check the shape of the real one
+// against.
+type splayRef struct {
+	root *shape
+}
+
+func (r *splayRef) rotateUp(path []*shape, i int) {
+	x, p := path[i], path[i-1]
+
+	if p.left == x {
+		p.left, x.right = x.right, p
+	} else {
+		p.right, x.left = x.left, p
+	}
+
+	if i == 1 {
+		r.root = x
+	} else if g := path[i-2]; g.left == p {
+		g.left = x
+	} else {
+		g.right = x
+	}
+
+	path[i-1] = x
+	copy(path[i:], path[i+1:])
+}
+
+// access finds v, or inserts it if insert is set, and splays it to the top. It
+// returns false, and leaves the tree alone, if v wasn't found or was already
+// there to insert.
+func (r *splayRef) access(v int, insert bool) bool {
+	var path []*shape
+	for s := r.root; s != nil; {
+		path = append(path, s)
+		if v == s.val {
+			break
+		}
+		if v < s.val {
+			s = s.left
+		} else {
+			s = s.right
+		}
+	}
+
+	found := len(path) > 0 && path[len(path)-1].val == v
+	if found == insert {
+		return false
+	}
+
+	if insert {
+		x := &shape{val: v}
+		if len(path) == 0 {
+			r.root = x
+			return true
+		}
+
+		p := path[len(path)-1]
+		if v < p.val {
+			p.left = x
+		} else {
+			p.right = x
+		}
+		path = append(path, x)
+	}
+
+	for i := len(path) - 1; i > 0; {
+		x, p := path[i], path[i-1]
+		if i == 1 {
+			r.rotateUp(path, i)
+			break
+		}
+
+		g := path[i-2]
+		if (g.left == p) == (p.left == x) {
+			r.rotateUp(path, i-1)
+			r.rotateUp(path, i-1)
+		} else {
+			r.rotateUp(path, i)
+			r.rotateUp(path, i-1)
+		}
+		i -= 2
+	}
+
+	return true
+}
+
+// String lays the tree out like Print does, but pads every value to two
+// digits.
+func (s *shape) String() string {
+	var sub func(s *shape, depth int) string
+	sub = func(s *shape, depth int) string {
+		if s == nil {
+			return ""
+		}
+		return sub(s.left, depth+1) + fmt.Sprintf("%*s%02d\n", depth, "", s.val) + sub(s.right, depth+1)
+	}
+
+	return sub(s, 0)
+}
+
+func TestSplay(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Contains", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Splay))
+		defer g.Close()
+
+		// each insert lands at the root, leaving a chain down the left
+		for i := 1; i <= 7; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		if got := printed(t, g); got != "      1\n     2\n    3\n   4\n  5\n 6\n7\n" {
+			t.Fatalf("Expected a chain down the left, got:\n%s", got)
+		}
+
+		// finding 1 brings it up and roughly halves the depth of the chain
+		if ok, _ := g.ContainsCtx(ctx, 1); !ok {
+			t.Fatal("Expected to find 1")
+		}
+
+		expected := "1\n   2\n    3\n  4\n   5\n 6\n  7\n"
+		if got := printed(t, g); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+
+		// a miss doesn't move anything
+		g.ContainsCtx(ctx, 100)
+		if got := printed(t, g); got != expected {
+			t.Fatalf("Expected a miss to leave the tree alone:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Shape", func(t *testing.T) {
+		g := goroutree.NewFunc(func(a, b int) int { return a - b }, goroutree.WithBalance(goroutree.Splay))
+		defer g.Close()
+
+		ref := &splayRef{}
+		rng := rand.New(rand.NewSource(1))
+
+		// the same accesses have to give the same tree as the reference, with
+		// every mix of zig, zig-zig and zig-zag along the way
+		for i := 0; i < 300; i++ {
+			v := rng.Intn(100)
+
+			if rng.Intn(3) == 0 {
+				ok, _ := g.InsertCtx(ctx, v)
+				if inserted := ref.access(v, true); ok != inserted {
+					t.Fatalf("Expected insert %d to return %v", v, inserted)
+				}
+			} else {
+				ok, _ := g.ContainsCtx(ctx, v)
+				if found := ref.access(v, false); ok != found {
+					t.Fatalf("Expected contains %d to return %v", v, found)
+				}
+			}
+		}
+
+		if got, expected := shapeOf(t, g).String(), ref.root.String(); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Delete", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Splay))
+		defer g.Close()
+
+		rng := rand.New(rand.NewSource(1))
+		model := map[int]bool{}
+
+		for i := 0; i < 2000; i++ {
+			v := rng.Intn(200)
+
+			switch rng.Intn(3) {
+			case 0:
+				if ok, _ := g.InsertCtx(ctx, v); ok == model[v] {
+					t.Fatalf("Expected insert %d to return %v", v, !model[v])
+				}
+				model[v] = true
+			case 1:
+				if ok, _ := g.DeleteCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected delete %d to return %v", v, model[v])
+				}
+				delete(model, v)
+			case 2:
+				if ok, _ := g.ContainsCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected contains %d to return %v", v, model[v])
+				}
+			}
+		}
+
+		var expected []int
+		for v := range model {
+			expected = append(expected, v)
+		}
+		slices.Sort(expected)
+
+		checkOrder(t, g, expected)
+	})
+	t.Run("Map", func(t *testing.T) {
+		m := goroutree.NewMap[int, int](goroutree.WithBalance(goroutree.Splay))
+		defer m.Close()
+
+		for i := 0; i < 50; i++ {
+			m.Put(i, i*i)
+		}
+
+		for i := 49; i >= 0; i -= 7 {
+			if v, ok, _ := m.Get(i); !ok || v != i*i {
+				t.Fatalf("Expected %d from get %d, got %d, %v", i*i, i, v, ok)
+			}
+			if v, ok, _ := m.Remove(i); !ok || v != i*i {
+				t.Fatalf("Expected %d from remove %d, got %d, %v", i*i, i, v, ok)
+			}
+		}
+	})
+}
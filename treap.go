@@ -0,0 +1,46 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+// priority draws a random priority for a new node in a Treap. Nodes can be
+// spawned from more than one goroutine, so the random source is shared behind
+// a lock.
+func (cfg *config[T]) priority() int64 {
+	cfg.rngMu.Lock()
+	defer cfg.rngMu.Unlock()
+
+	return cfg.rng.Int63()
+}
+
+// rebalanceTreap keeps this node's priority at least as high as both of its
+// children's. If one of them beats it, that child is rotated up, and the value
+// this node held sinks on down from there as part of the rotation.
+//
+// After an insert only the new node can be out of place, so it just rotates
+// up one level at a time on the way back. A delete that pulls up the successor
+// puts that value, with its low priority, at the top of a subtree, and it
+// sinks back down the same way.
+func (n *node[T]) rebalanceTreap() {
+	up := &n.left
+	if n.right.ch != nil && (n.left.ch == nil || n.right.prio > n.left.prio) {
+		up = &n.right
+	}
+
+	if up.ch == nil || up.prio <= n.prio {
+		return
+	}
+
+	n.rotate(up == &n.left)
+}
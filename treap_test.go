@@ -0,0 +1,102 @@
+package goroutree_test
+
+import (
+	"context"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+func TestTreap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Seeded", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(1))
+		defer g.Close()
+
+		for i := 1; i <= 7; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// with seed 1, 2 draws the highest priority, then 6 and 3
+		expected := " 1\n2\n  3\n   4\n    5\n 6\n  7\n"
+		if got := printed(t, g); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+
+		// 5 pulls up out of the right side to replace 2, and has to sink back
+		// down below 6 and 3, which leaves 6 on top
+		g.DeleteCtx(ctx, 2)
+
+		expected = "  1\n 3\n  4\n   5\n6\n 7\n"
+		if got := printed(t, g); got != expected {
+			t.Fatalf("Expected:\n%s\ngot:\n%s", expected, got)
+		}
+	})
+	t.Run("Reproducible", func(t *testing.T) {
+		order := rand.New(rand.NewSource(7)).Perm(100)
+
+		var shapes []string
+		for i := 0; i < 2; i++ {
+			g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(42))
+			for _, v := range order {
+				g.InsertCtx(ctx, v)
+			}
+
+			shapes = append(shapes, printed(t, g))
+			g.Close()
+		}
+
+		if shapes[0] != shapes[1] {
+			t.Fatalf("Expected the same seed to give the same tree, got:\n%s\nand:\n%s", shapes[0], shapes[1])
+		}
+	})
+	t.Run("Depth", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap), goroutree.WithSeed(1))
+		defer g.Close()
+
+		for i := 0; i < 1000; i++ {
+			g.InsertCtx(ctx, i)
+		}
+
+		// nothing guarantees this, but sorted inserts into an unbalanced tree
+		// would be 1000 deep
+		if h := shapeOf(t, g).height(); h > 40 {
+			t.Fatalf("Expected a height of at most 40, got %d", h)
+		}
+		checkLen(t, g, 1000)
+	})
+	t.Run("Random", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.WithBalance(goroutree.Treap))
+		defer g.Close()
+
+		rng := rand.New(rand.NewSource(1))
+		model := map[int]bool{}
+
+		for i := 0; i < 2000; i++ {
+			v := rng.Intn(200)
+
+			if rng.Intn(2) == 0 {
+				if ok, _ := g.InsertCtx(ctx, v); ok == model[v] {
+					t.Fatalf("Expected insert %d to return %v", v, !model[v])
+				}
+				model[v] = true
+			} else {
+				if ok, _ := g.DeleteCtx(ctx, v); ok != model[v] {
+					t.Fatalf("Expected delete %d to return %v", v, model[v])
+				}
+				delete(model, v)
+			}
+		}
+
+		var expected []int
+		for v := range model {
+			expected = append(expected, v)
+		}
+		slices.Sort(expected)
+
+		checkOrder(t, g, expected)
+	})
+}
@@ -0,0 +1,235 @@
+//   Copyright 2016 Scott Mansfield
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package goroutree
+
+import "context"
+
+// entry is a single value streamed out of the tree by a walk, with everything
+// else its node holds.
+type entry[T any] struct {
+	val     T
+	payload any
+	count   int
+}
+
+// walkCmd streams the values of a subtree out in order, the same way ctPrint
+// does: every node holds on to the command until its whole subtree is done,
+// so nothing else can happen in that subtree in the meantime. Nodes outside
+// [lo, hi] are skipped, as are the subtrees that can only hold values outside
+// it. A nil bound means there's no bound on that side.
+type walkCmd[T any] struct {
+	reschan chan error
+	out     chan entry[T]
+	done    <-chan struct{}
+	lo, hi  *T
+	reverse bool
+}
+
+func (c walkCmd[T]) typ() cmdType {
+	return ctWalk
+}
+
+// walker is one walk in progress.
+type walker[T any] struct {
+	entries chan entry[T]
+	reschan chan error
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// startWalk sends a walk down the tree. The caller must call each on the
+// walker it gets back, or the nodes in the tree will be stuck waiting to hand
+// it values.
+func (g *Goroutree[T]) startWalk(ctx context.Context, lo, hi *T, reverse bool) (*walker[T], error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	w := &walker[T]{
+		entries: make(chan entry[T]),
+		reschan: make(chan error, 1),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	c := walkCmd[T]{
+		reschan: w.reschan,
+		out:     w.entries,
+		done:    ctx.Done(),
+		lo:      lo,
+		hi:      hi,
+		reverse: reverse,
+	}
+
+	if err := g.handoff(ctx, c); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// each calls fn with every value the walk finds, on the calling goroutine,
+// until fn returns false or the walk's context is done. Either way it waits
+// for every node to let go of the walk before it returns.
+func (w *walker[T]) each(fn func(val T, count int) bool) error {
+	defer w.cancel()
+
+	stopped := false
+	for {
+		select {
+		case e := <-w.entries:
+			// a node can still get a value through after the walk has been
+			// cancelled, since it's waiting on both at once.
+			if stopped {
+				continue
+			}
+
+			if !fn(e.val, e.count) {
+				stopped = true
+				w.cancel()
+			}
+
+		case err := <-w.reschan:
+			if err == nil && !stopped {
+				err = w.ctx.Err()
+			}
+			return err
+		}
+	}
+}
+
+// Range streams the values between lo and hi (inclusive) out in increasing
+// order. Subtrees that can't hold anything in the range are never visited.
+// In a Multiset tree each value is sent as many times as it was inserted.
+//
+// Like Print, the scan holds on to each node until everything under it has
+// been sent, so the tree can't do anything else while the channel is being
+// read. Read it until it's closed, or cancel ctx to stop early and let go of
+// the nodes.
+//
+// Once the channel is closed, the func returned with it reports why: nil if
+// the whole range was sent, the error from the compare if a value in the tree
+// couldn't be compared to lo or hi, or ctx's error if the scan was cancelled.
+// It blocks until the channel has been closed.
+func (g *Goroutree[T]) Range(ctx context.Context, lo, hi T) (<-chan T, func() error, error) {
+	w, err := g.startWalk(ctx, &lo, &hi, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make(chan T)
+	done := make(chan struct{})
+	var walkErr error
+
+	go func() {
+		defer close(done)
+		defer close(out)
+
+		walkErr = w.each(func(val T, count int) bool {
+			for i := 0; i < count; i++ {
+				select {
+				case out <- val:
+				case <-ctx.Done():
+					return false
+				}
+			}
+
+			return true
+		})
+
+		if walkErr == nil {
+			walkErr = ctx.Err()
+		}
+	}()
+
+	return out, func() error {
+		<-done
+		return walkErr
+	}, nil
+}
+
+// stopped reports whether the walk has been called off.
+func (c walkCmd[T]) stopped() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *node[T]) walk(c walkCmd[T]) {
+	c.reschan <- n.walkSubtree(c)
+}
+
+// walkSubtree does the work for walk and returns any error from comparing the
+// bounds, either here or further down.
+func (n *node[T]) walkSubtree(c walkCmd[T]) error {
+	// work out which sides could have anything in range, and whether this
+	// node is in range itself.
+	goLeft, goRight, emit := true, true, true
+
+	if c.lo != nil {
+		comparison, err := n.cfg.compare(n.val, *c.lo)
+		if err != nil {
+			return err
+		}
+
+		goLeft = comparison > 0
+		emit = comparison >= 0
+	}
+
+	if c.hi != nil {
+		comparison, err := n.cfg.compare(n.val, *c.hi)
+		if err != nil {
+			return err
+		}
+
+		goRight = comparison < 0
+		emit = emit && comparison <= 0
+	}
+
+	first, second := n.left, n.right
+	goFirst, goSecond := goLeft, goRight
+	if c.reverse {
+		first, second = second, first
+		goFirst, goSecond = goSecond, goFirst
+	}
+
+	childcmd := c
+	childcmd.reschan = make(chan error)
+
+	if goFirst && first.ch != nil && !c.stopped() {
+		first.ch <- childcmd
+		if err := <-childcmd.reschan; err != nil {
+			return err
+		}
+	}
+
+	if emit && !c.stopped() {
+		select {
+		case c.out <- entry[T]{val: n.val, payload: n.payload, count: n.count}:
+		case <-c.done:
+		}
+	}
+
+	if goSecond && second.ch != nil && !c.stopped() {
+		second.ch <- childcmd
+		if err := <-childcmd.reschan; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
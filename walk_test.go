@@ -0,0 +1,188 @@
+package goroutree_test
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ScottMansfield/goroutree"
+)
+
+// balancedOrder inserts 0 through 14 so they make a perfectly balanced tree
+//
+//	              7
+//	      3               11
+//	  1       5       9       13
+//	0   2   4   6   8   10  12  14
+var balancedOrder = []int{7, 3, 1, 0, 2, 5, 4, 6, 11, 9, 8, 10, 13, 12, 14}
+
+func collect(t *testing.T, c <-chan int) []int {
+	t.Helper()
+
+	var vals []int
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case v, ok := <-c:
+			if !ok {
+				return vals
+			}
+			vals = append(vals, v)
+
+		case <-timeout:
+			t.Fatalf("Timed out waiting for the range to finish, got %v so far", vals)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		c, wait, err := g.Range(ctx, 0, 10)
+		if err != nil {
+			t.Fatalf("Expected no error from range, got %v", err)
+		}
+
+		if vals := collect(t, c); len(vals) != 0 {
+			t.Fatalf("Expected nothing from range, got %v", vals)
+		}
+		if err := wait(); err != nil {
+			t.Fatalf("Expected no error after range, got %v", err)
+		}
+	})
+	t.Run("Bounds", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		for _, tc := range []struct {
+			lo, hi int
+			gold   []int
+		}{
+			{0, 14, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14}},
+			{4, 9, []int{4, 5, 6, 7, 8, 9}},
+			{7, 7, []int{7}},
+			{-5, 1, []int{0, 1}},
+			{13, 50, []int{13, 14}},
+			{20, 30, nil},
+			{9, 4, nil},
+		} {
+			c, wait, err := g.Range(ctx, tc.lo, tc.hi)
+			if err != nil {
+				t.Fatalf("Expected no error from range, got %v", err)
+			}
+
+			if vals := collect(t, c); !reflect.DeepEqual(vals, tc.gold) {
+				t.Fatalf("Expected %v from range [%d, %d], got %v", tc.gold, tc.lo, tc.hi, vals)
+			}
+			if err := wait(); err != nil {
+				t.Fatalf("Expected no error after range, got %v", err)
+			}
+		}
+	})
+	t.Run("Prunes", func(t *testing.T) {
+		var compares int64
+		g := goroutree.NewFunc(func(a, b int) int {
+			atomic.AddInt64(&compares, 1)
+			return a - b
+		})
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		atomic.StoreInt64(&compares, 0)
+
+		c, _, _ := g.Range(ctx, 4, 4)
+		if vals := collect(t, c); !reflect.DeepEqual(vals, []int{4}) {
+			t.Fatalf("Expected [4] from range, got %v", vals)
+		}
+
+		// only 7, 3, 5 and 4 should be looked at, two compares each
+		if n := atomic.LoadInt64(&compares); n != 8 {
+			t.Fatalf("Expected 8 compares for a narrow range, got %d", n)
+		}
+	})
+	t.Run("Multiset", func(t *testing.T) {
+		g := goroutree.NewOrdered[int](goroutree.Multiset())
+		defer g.Close()
+
+		for _, i := range []int{2, 1, 2, 3} {
+			g.InsertCtx(ctx, i)
+		}
+
+		c, _, _ := g.Range(ctx, 0, 10)
+		if vals := collect(t, c); !reflect.DeepEqual(vals, []int{1, 2, 2, 3}) {
+			t.Fatalf("Expected [1 2 2 3] from range, got %v", vals)
+		}
+	})
+	t.Run("StopEarly", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, i)
+		}
+
+		rctx, cancel := context.WithCancel(ctx)
+		c, wait, _ := g.Range(rctx, 0, 14)
+
+		if v := <-c; v != 0 {
+			t.Fatalf("Expected 0 first from range, got %d", v)
+		}
+
+		cancel()
+
+		// the nodes should all let go, so the tree works again
+		tctx, tcancel := context.WithTimeout(ctx, 5*time.Second)
+		defer tcancel()
+
+		if ok, err := g.InsertCtx(tctx, 15); !ok || err != nil {
+			t.Fatalf("Expected a true result from inserting after a cancelled range, got %v, %v", ok, err)
+		}
+
+		collect(t, c)
+		if err := wait(); err != context.Canceled {
+			t.Fatalf("Expected context.Canceled after a cancelled range, got %v", err)
+		}
+	})
+	t.Run("NotComparable", func(t *testing.T) {
+		g := goroutree.New()
+		defer g.Close()
+
+		for _, i := range balancedOrder {
+			g.InsertCtx(ctx, goroutree.Int(i))
+		}
+
+		c, wait, err := g.Range(ctx, Str("a"), Str("z"))
+		if err != nil {
+			t.Fatalf("Expected no error from range, got %v", err)
+		}
+
+		for v := range c {
+			t.Fatalf("Expected nothing from range, got %v", v)
+		}
+		if err := wait(); err != goroutree.NotComparable {
+			t.Fatalf("Expected NotComparable after range, got %v", err)
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		g := goroutree.NewOrdered[int]()
+		g.Close()
+
+		if _, _, err := g.Range(ctx, 0, 1); err != goroutree.ErrClosed {
+			t.Fatalf("Expected ErrClosed from range, got %v", err)
+		}
+	})
+}